@@ -3,27 +3,78 @@ package main
 import (
 	"context"
 	"log"
+	"time"
+	// exchange timezones are needed to find when the trading day of a split starts
+	_ "time/tzdata"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	logger "github.com/lenoobz/aws-lambda-logger"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/bars"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/corpactions"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/deadletter"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/enrichment"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/fx"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/retries"
 )
 
+// dateLayout is the layout of the event dates
+const dateLayout = "2006-01-02"
+
+// Lambda jobs
+const (
+	pricesJob    = "prices"
+	backfillJob  = "backfill"
+	dividendsJob = "dividends"
+	splitsJob    = "splits"
+	enrichJob    = "enrich"
+	fxJob        = "fx"
+)
+
+// ScrapeEvent is the payload the lambda is invoked with
+type ScrapeEvent struct {
+	// Job is the job to run, prices when empty
+	Job string `json:"job,omitempty"`
+	// ExtendedHours only stores pre-market and after-hours prices of quotes outside regular hours
+	ExtendedHours bool `json:"extendedHours,omitempty"`
+	// From is the first day (YYYY-MM-DD) of a backfill, of the scraped dividend events or of the
+	// price history splits are detected in
+	From string `json:"from,omitempty"`
+	// To is the last day (YYYY-MM-DD) of a backfill, today when empty
+	To string `json:"to,omitempty"`
+	// Tickers limits a price scrape, a backfill, a dividend scrape, a split adjustment or an
+	// enrichment to the given tickers
+	Tickers []string `json:"tickers,omitempty"`
+	// Types, AssetClasses and Currencies limit a price scrape to the assets of the given values
+	Types        []string `json:"types,omitempty"`
+	AssetClasses []string `json:"assetClasses,omitempty"`
+	Currencies   []string `json:"currencies,omitempty"`
+	// IncludeDisabled and IncludeDeleted also scrape the prices of disabled and deleted assets
+	IncludeDisabled bool `json:"includeDisabled,omitempty"`
+	IncludeDeleted  bool `json:"includeDeleted,omitempty"`
+	// Checkpoint makes a backfill, a dividend scrape or an enrichment process the next page of assets
+	// of its own checkpoint instead of all assets
+	Checkpoint bool `json:"checkpoint,omitempty"`
+	// FxCurrencies limits an fx scrape to the rates of the given currencies, the currencies of all
+	// assets when empty
+	FxCurrencies []string `json:"fxCurrencies,omitempty"`
+}
+
 func main() {
 	lambda.Start(lambdaHandler)
 }
 
-func lambdaHandler(ctx context.Context) {
+func lambdaHandler(ctx context.Context, event ScrapeEvent) {
 	log.Println("lambda handler is called")
 
-	appConf := config.AppConf
-
 	// create new logger
 	zap, err := logger.NewZapLogger()
 	if err != nil {
@@ -31,6 +82,28 @@ func lambdaHandler(ctx context.Context) {
 	}
 	defer zap.Close()
 
+	switch event.Job {
+	case pricesJob, "":
+		runPriceJob(event, zap)
+	case backfillJob:
+		runBackfillJob(event, zap)
+	case dividendsJob:
+		runDividendsJob(event, zap)
+	case splitsJob:
+		runSplitsJob(event, zap)
+	case enrichJob:
+		runEnrichJob(event, zap)
+	case fxJob:
+		runFxJob(event, zap)
+	default:
+		zap.Error(ctx, "unknown job", "job", event.Job)
+	}
+}
+
+// runPriceJob scrapes the next page of asset prices from the checkpoint
+func runPriceJob(event ScrapeEvent, zap logger.ContextLog) {
+	appConf := config.AppConf
+
 	// create new repository
 	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
 	if err != nil {
@@ -52,13 +125,329 @@ func lambdaHandler(ctx context.Context) {
 	}
 	defer checkpointRepo.Close()
 
+	// create new repository
+	retryRepo, err := repos.NewScrapeRetryMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create scrape retry mongo failed")
+	}
+	defer retryRepo.Close()
+
+	// create new repository
+	failureRepo, err := repos.NewScrapeFailureMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create scrape failure mongo failed")
+	}
+	defer failureRepo.Close()
+
+	// create new repository
+	eventRepo, err := repos.NewAssetEventMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset event mongo failed")
+	}
+	defer eventRepo.Close()
+
+	// create new repository
+	fxRateRepo, err := repos.NewFxRateMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create fx rate mongo failed")
+	}
+	defer fxRateRepo.Close()
+
 	// create new services
-	checkpointService := checkpoint.NewService(checkpointRepo, zap)
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
 	assetService := assets.NewService(assetRepo, *checkpointService, zap)
 	priceService := price.NewService(assetPriceRepo, zap)
+	retryService := retries.NewService(retryRepo, zap)
+	failureService := deadletter.NewService(failureRepo, assetService, appConf.Scraper.QuarantineThreshold, zap)
+	eventService := events.NewService(eventRepo, zap)
+	fxService := fx.NewService(fxRateRepo, appConf.Scraper.BaseCurrency, appConf.Scraper.MaxFxRateAgeMS, zap)
+
+	// create new price source
+	priceSource, err := scraper.NewPriceSource(&appConf.Scraper, zap)
+	if err != nil {
+		log.Fatal("create price source failed")
+	}
 
 	// create new scraper jobs
-	job := scraper.NewAssetPriceScraper(assetService, priceService, zap)
+	job := scraper.NewAssetPriceScraper(priceSource, assetService, priceService, retryService, failureService, eventService, fxService, &appConf.Scraper, zap)
+	job.SetExtendedHoursOnly(event.ExtendedHours)
+	job.SetAssetFilter(&entities.AssetFilter{
+		IncludeDisabled: event.IncludeDisabled,
+		IncludeDeleted:  event.IncludeDeleted,
+		Types:           event.Types,
+		AssetClasses:    event.AssetClasses,
+		Currencies:      event.Currencies,
+		Tickers:         event.Tickers,
+	})
 	job.ScrapeAssetPricesFromCheckpoint(consts.PAGE_SIZE)
 	defer job.Close()
 }
+
+// runBackfillJob downloads historical daily bars
+func runBackfillJob(event ScrapeEvent, zap logger.ContextLog) {
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	from, err := time.Parse(dateLayout, event.From)
+	if err != nil {
+		zap.Error(ctx, "invalid backfill from date", "error", err, "from", event.From)
+		return
+	}
+
+	to := time.Now().UTC()
+	if event.To != "" {
+		if to, err = time.Parse(dateLayout, event.To); err != nil {
+			zap.Error(ctx, "invalid backfill to date", "error", err, "to", event.To)
+			return
+		}
+	}
+
+	// create new repository
+	barRepo, err := repos.NewPriceBarMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create price bar mongo failed")
+	}
+	defer barRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset mongo failed")
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create checkpoint mongo failed")
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	barService := bars.NewService(barRepo, zap)
+
+	// create new bar source
+	barSource, err := scraper.NewYahooChartSource(&appConf.Scraper, zap)
+	if err != nil {
+		log.Fatal("create bar source failed")
+	}
+
+	// create new backfill jobs
+	job := scraper.NewBarBackfiller(barSource, assetService, barService, zap)
+	if event.Checkpoint {
+		job.BackfillFromCheckpoint(from, to.Add(24*time.Hour), consts.PAGE_SIZE)
+	} else {
+		job.Backfill(from, to.Add(24*time.Hour), event.Tickers)
+	}
+	defer job.Close()
+}
+
+// runDividendsJob scrapes dividend and split events and updates asset distributions
+func runDividendsJob(event ScrapeEvent, zap logger.ContextLog) {
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	from := time.Now().UTC().AddDate(-2, 0, 0)
+	if event.From != "" {
+		var err error
+		if from, err = time.Parse(dateLayout, event.From); err != nil {
+			zap.Error(ctx, "invalid dividends from date", "error", err, "from", event.From)
+			return
+		}
+	}
+
+	// create new repository
+	eventRepo, err := repos.NewAssetEventMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset event mongo failed")
+	}
+	defer eventRepo.Close()
+
+	// create new repository
+	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset price mongo failed")
+	}
+	defer assetPriceRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset mongo failed")
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create checkpoint mongo failed")
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	priceService := price.NewService(assetPriceRepo, zap)
+	eventService := events.NewService(eventRepo, zap)
+
+	// create new event source
+	eventSource, err := scraper.NewYahooChartSource(&appConf.Scraper, zap)
+	if err != nil {
+		log.Fatal("create event source failed")
+	}
+
+	// create new dividend jobs
+	job := scraper.NewDividendScraper(eventSource, assetService, priceService, eventService, zap)
+	if event.Checkpoint {
+		job.ScrapeDividendsFromCheckpoint(from, consts.PAGE_SIZE)
+	} else {
+		job.ScrapeDividends(from, event.Tickers)
+	}
+	defer job.Close()
+}
+
+// runSplitsJob detects splits and adjusts the stored price history
+func runSplitsJob(event ScrapeEvent, zap logger.ContextLog) {
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	from := time.Now().UTC().AddDate(0, 0, -30)
+	if event.From != "" {
+		var err error
+		if from, err = time.Parse(dateLayout, event.From); err != nil {
+			zap.Error(ctx, "invalid splits from date", "error", err, "from", event.From)
+			return
+		}
+	}
+
+	// create new repository
+	eventRepo, err := repos.NewAssetEventMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset event mongo failed")
+	}
+	defer eventRepo.Close()
+
+	// create new repository
+	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset price mongo failed")
+	}
+	defer assetPriceRepo.Close()
+
+	// create new repository
+	barRepo, err := repos.NewPriceBarMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create price bar mongo failed")
+	}
+	defer barRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset mongo failed")
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create checkpoint mongo failed")
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	priceService := price.NewService(assetPriceRepo, zap)
+	eventService := events.NewService(eventRepo, zap)
+	barService := bars.NewService(barRepo, zap)
+	corpActionService := corpactions.NewService(priceService, eventService, barService, zap)
+
+	// create new split jobs
+	job := scraper.NewSplitAdjuster(assetService, corpActionService, zap)
+	job.AdjustSplits(from, event.Tickers)
+	defer job.Close()
+}
+
+// runEnrichJob fills missing asset metadata from yahoo and flags currency mismatches
+func runEnrichJob(event ScrapeEvent, zap logger.ContextLog) {
+	appConf := config.AppConf
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset mongo failed")
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create checkpoint mongo failed")
+	}
+	defer checkpointRepo.Close()
+
+	// create new metadata source
+	metadataSource, err := scraper.NewYahooQuoteSource(&appConf.Scraper, zap)
+	if err != nil {
+		log.Fatal("create metadata source failed")
+	}
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	enrichmentService := enrichment.NewService(metadataSource, assetService, zap)
+
+	// create new enrich jobs
+	job := scraper.NewAssetEnricher(assetService, enrichmentService, zap)
+	if event.Checkpoint {
+		job.EnrichAssetsFromCheckpoint(consts.PAGE_SIZE)
+	} else {
+		job.EnrichAssets(event.Tickers)
+	}
+	defer job.Close()
+}
+
+// runFxJob scrapes the fx rates of asset currencies to the base currency
+func runFxJob(event ScrapeEvent, zap logger.ContextLog) {
+	appConf := config.AppConf
+
+	// create new repository
+	fxRateRepo, err := repos.NewFxRateMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create fx rate mongo failed")
+	}
+	defer fxRateRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create asset mongo failed")
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		log.Fatal("create checkpoint mongo failed")
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	fxService := fx.NewService(fxRateRepo, appConf.Scraper.BaseCurrency, appConf.Scraper.MaxFxRateAgeMS, zap)
+
+	// create new price source
+	priceSource, err := scraper.NewPriceSource(&appConf.Scraper, zap)
+	if err != nil {
+		log.Fatal("create price source failed")
+	}
+
+	// create new fx jobs
+	job := scraper.NewFxScraper(priceSource, assetService, fxService, zap)
+	job.ScrapeFxRates(event.FxCurrencies)
+	defer job.Close()
+}
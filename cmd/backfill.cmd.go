@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/bars"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+)
+
+// runBackfill downloads historical daily bars
+func runBackfill(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fromFlag := flags.String("from", "", "first day to backfill (YYYY-MM-DD, required)")
+	toFlag := flags.String("to", "", "last day to backfill (YYYY-MM-DD, defaults to today)")
+	tickersFlag := flags.String("tickers", "", "comma separated tickers to backfill, defaults to all assets")
+	fromCheckpoint := flags.Bool("checkpoint", false, "backfill the next page of assets from the backfill checkpoint instead of all assets")
+	flags.Parse(args)
+
+	if *fromFlag == "" {
+		return fmt.Errorf("missing -from date")
+	}
+
+	from, err := parseDate(*fromFlag, time.Time{})
+	if err != nil {
+		return fmt.Errorf("invalid -from date: %w", err)
+	}
+
+	to, err := parseDate(*toFlag, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("invalid -to date: %w", err)
+	}
+
+	appConf := config.AppConf
+
+	// create new repository
+	barRepo, err := repos.NewPriceBarMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create price bar mongo failed: %w", err)
+	}
+	defer barRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	barService := bars.NewService(barRepo, zap)
+
+	// create new bar source
+	barSource, err := scraper.NewYahooChartSource(&appConf.Scraper, zap)
+	if err != nil {
+		return fmt.Errorf("create bar source failed: %w", err)
+	}
+
+	job := scraper.NewBarBackfiller(barSource, assetService, barService, zap)
+	if *fromCheckpoint {
+		job.BackfillFromCheckpoint(from, to.Add(24*time.Hour), consts.PAGE_SIZE)
+	} else {
+		job.Backfill(from, to.Add(24*time.Hour), splitList(*tickersFlag))
+	}
+	defer job.Close()
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+)
+
+// checkpointActions are the actions of the checkpoint command
+var checkpointActions = map[string]bool{
+	"status": true,
+	"reset":  true,
+	"set":    true,
+}
+
+// runCheckpoint shows, resets or sets the checkpoint of a job
+func runCheckpoint(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	nameFlag := flags.String("name", consts.PRICES_CHECKPOINT, "checkpoint of the job (prices, dividends, backfill or enrich)")
+	indexFlag := flags.Int64("index", -1, "page the next run of the job leases, required by set")
+	tickers := flags.String("tickers", "", "comma separated tickers of the filtered run the checkpoint pages for")
+	types := flags.String("types", "", "comma separated asset types of the filtered run the checkpoint pages for")
+	classes := flags.String("classes", "", "comma separated asset classes of the filtered run the checkpoint pages for")
+	currencies := flags.String("currencies", "", "comma separated currencies of the filtered run the checkpoint pages for")
+	includeDisabled := flags.Bool("include-disabled", false, "the filtered run also scrapes disabled assets")
+	includeDeleted := flags.Bool("include-deleted", false, "the filtered run also scrapes deleted assets")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s checkpoint status|reset|set [-name NAME] [-index N] [filter flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 || !checkpointActions[args[0]] {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	action := args[0]
+	flags.Parse(args[1:])
+
+	// a filtered run pages with the checkpoint of its filter
+	assetFilter := &entities.AssetFilter{
+		IncludeDisabled: *includeDisabled,
+		IncludeDeleted:  *includeDeleted,
+		Types:           splitList(*types),
+		AssetClasses:    splitList(*classes),
+		Currencies:      splitList(*currencies),
+		Tickers:         splitList(*tickers),
+	}
+	name := assets.CheckpointName(*nameFlag, assetFilter)
+
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+
+	switch action {
+	case "status":
+		status, err := assetService.GetCheckpointStatus(ctx, *nameFlag, assetFilter)
+		if err != nil {
+			return fmt.Errorf("get checkpoint status failed: %w", err)
+		}
+
+		printCheckpointStatus(status)
+	case "reset":
+		if err := checkpointService.ResetCheckpoint(ctx, name); err != nil {
+			return fmt.Errorf("reset checkpoint failed: %w", err)
+		}
+
+		fmt.Printf("reset checkpoint %s, the next run starts at page 0\n", name)
+	case "set":
+		if *indexFlag < 0 {
+			return fmt.Errorf("set requires -index")
+		}
+
+		status, err := assetService.GetCheckpointStatus(ctx, *nameFlag, assetFilter)
+		if err != nil {
+			return fmt.Errorf("get checkpoint status failed: %w", err)
+		}
+
+		// the pages are only known once a run has leased one with its page size
+		if status.NumPages == 0 {
+			return fmt.Errorf("checkpoint %s has no pages yet, run the job first", name)
+		}
+
+		if *indexFlag >= status.NumPages {
+			return fmt.Errorf("page %d is past the last page %d of checkpoint %s", *indexFlag, status.NumPages-1, name)
+		}
+
+		if err := checkpointService.SetCheckpoint(ctx, name, *indexFlag); err != nil {
+			return fmt.Errorf("set checkpoint failed: %w", err)
+		}
+
+		fmt.Printf("set checkpoint %s, the next run starts at page %d\n", name, *indexFlag)
+	}
+
+	return nil
+}
+
+// printCheckpointStatus prints how far a checkpoint is through the assets
+func printCheckpointStatus(status *entities.CheckpointStatus) {
+	fmt.Printf("%-16s %s\n", "checkpoint", status.Name)
+
+	cp := status.Checkpoint
+	if cp == nil {
+		fmt.Printf("%-16s %d, no page leased yet\n", "assets", status.NumAssets)
+		return
+	}
+
+	fmt.Printf("%-16s %d in %d pages of %d\n", "assets", status.NumAssets, status.NumPages, cp.PageSize)
+	fmt.Printf("%-16s page %d, %d of %d assets (%.1f%%)\n", "position", cp.PageIndex, status.ProcessedAssets, status.NumAssets, status.Progress)
+	fmt.Printf("%-16s %d\n", "next page", status.NextPageIndex)
+
+	if status.PageInterval > 0 {
+		fmt.Printf("%-16s %s\n", "page interval", formatSeconds(status.PageInterval))
+		fmt.Printf("%-16s %s\n", "full cycle", formatSeconds(status.CycleDuration))
+		fmt.Printf("%-16s %s\n", "cycle remaining", formatSeconds(status.RemainingDuration))
+	} else {
+		fmt.Printf("%-16s not enough completed pages to estimate\n", "full cycle")
+	}
+
+	for _, lease := range cp.Leases {
+		fmt.Printf("%-16s page %d by %s, expires %s\n", "lease", lease.PageIndex, lease.Owner, formatUnix(lease.ExpiresAt))
+	}
+
+	pages := cp.CompletedPages
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].PageIndex < pages[j].PageIndex
+	})
+
+	for _, page := range pages {
+		fmt.Printf("%-16s page %d at %s\n", "completed", page.PageIndex, formatUnix(page.CompletedAt))
+	}
+}
+
+// formatSeconds formats a number of seconds as a duration
+func formatSeconds(seconds int64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+// formatUnix formats unix seconds as an RFC3339 time
+func formatUnix(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+// runDividends scrapes dividend and split events and updates asset distributions
+func runDividends(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("dividends", flag.ExitOnError)
+	fromFlag := flags.String("from", "", "first day to scrape events from (YYYY-MM-DD, defaults to two years ago)")
+	tickersFlag := flags.String("tickers", "", "comma separated tickers to scrape, defaults to all assets")
+	fromCheckpoint := flags.Bool("checkpoint", false, "scrape the next page of assets from the dividends checkpoint instead of all assets")
+	flags.Parse(args)
+
+	from, err := parseDate(*fromFlag, time.Now().UTC().AddDate(-2, 0, 0))
+	if err != nil {
+		return fmt.Errorf("invalid -from date: %w", err)
+	}
+
+	appConf := config.AppConf
+
+	// create new repository
+	eventRepo, err := repos.NewAssetEventMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset event mongo failed: %w", err)
+	}
+	defer eventRepo.Close()
+
+	// create new repository
+	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset price mongo failed: %w", err)
+	}
+	defer assetPriceRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	priceService := price.NewService(assetPriceRepo, zap)
+	eventService := events.NewService(eventRepo, zap)
+
+	// create new event source
+	eventSource, err := scraper.NewYahooChartSource(&appConf.Scraper, zap)
+	if err != nil {
+		return fmt.Errorf("create event source failed: %w", err)
+	}
+
+	job := scraper.NewDividendScraper(eventSource, assetService, priceService, eventService, zap)
+	if *fromCheckpoint {
+		job.ScrapeDividendsFromCheckpoint(from, consts.PAGE_SIZE)
+	} else {
+		job.ScrapeDividends(from, splitList(*tickersFlag))
+	}
+	defer job.Close()
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/enrichment"
+)
+
+// runEnrich fills missing asset metadata from yahoo and flags currency mismatches
+func runEnrich(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("enrich", flag.ExitOnError)
+	tickersFlag := flags.String("tickers", "", "comma separated tickers to enrich, defaults to all assets")
+	fromCheckpoint := flags.Bool("checkpoint", false, "enrich the next page of assets from the enrich checkpoint instead of all assets")
+	flags.Parse(args)
+
+	appConf := config.AppConf
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new metadata source
+	metadataSource, err := scraper.NewYahooQuoteSource(&appConf.Scraper, zap)
+	if err != nil {
+		return fmt.Errorf("create metadata source failed: %w", err)
+	}
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	enrichmentService := enrichment.NewService(metadataSource, assetService, zap)
+
+	job := scraper.NewAssetEnricher(assetService, enrichmentService, zap)
+	if *fromCheckpoint {
+		job.EnrichAssetsFromCheckpoint(consts.PAGE_SIZE)
+	} else {
+		job.EnrichAssets(splitList(*tickersFlag))
+	}
+	defer job.Close()
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/fx"
+)
+
+// runFx scrapes the fx rates of asset currencies to the base currency
+func runFx(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("fx", flag.ExitOnError)
+	currenciesFlag := flags.String("currencies", "", "comma separated currencies to scrape the rates of, defaults to the currencies of all assets")
+	flags.Parse(args)
+
+	appConf := config.AppConf
+
+	// create new repository
+	fxRateRepo, err := repos.NewFxRateMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create fx rate mongo failed: %w", err)
+	}
+	defer fxRateRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	fxService := fx.NewService(fxRateRepo, appConf.Scraper.BaseCurrency, appConf.Scraper.MaxFxRateAgeMS, zap)
+
+	// create new price source
+	priceSource, err := scraper.NewPriceSource(&appConf.Scraper, zap)
+	if err != nil {
+		return fmt.Errorf("create price source failed: %w", err)
+	}
+
+	job := scraper.NewFxScraper(priceSource, assetService, fxService, zap)
+	job.ScrapeFxRates(splitList(*currenciesFlag))
+	defer job.Close()
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/importer"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+)
+
+// runImport upserts the assets of a csv or json file by ticker
+func runImport(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "file format, csv or json, taken from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "report the changes without writing them")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s import [flags] <file>\n", os.Args[0])
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	imported, err := importer.ReadAssetFile(flags.Arg(0), *format)
+	if err != nil {
+		return fmt.Errorf("read asset file failed: %w", err)
+	}
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+
+	report, err := assetService.ImportAssets(ctx, imported, *dryRun)
+	if report != nil {
+		printImportReport(report)
+	}
+	if err != nil {
+		return fmt.Errorf("import assets failed: %w", err)
+	}
+
+	return nil
+}
+
+// printImportReport prints the invalid rows, the changes and the summary of an import
+func printImportReport(report *entities.AssetImportReport) {
+	for _, invalid := range report.Invalid {
+		fmt.Printf("! row %d %s: %s\n", invalid.Row, invalid.Ticker, invalid.Reason)
+	}
+
+	for _, change := range report.Inserted {
+		fmt.Printf("+ %s\n", change.Ticker)
+		for _, field := range change.Fields {
+			fmt.Printf("    %s: %s\n", field.Field, field.New)
+		}
+	}
+
+	for _, change := range report.Updated {
+		fmt.Printf("~ %s\n", change.Ticker)
+		for _, field := range change.Fields {
+			fmt.Printf("    %s: %q -> %q\n", field.Field, field.Old, field.New)
+		}
+	}
+
+	verb := "imported"
+	if len(report.Invalid) > 0 {
+		verb = "nothing imported"
+	} else if report.DryRun {
+		verb = "would import"
+	}
+
+	fmt.Printf("%s: %d inserted, %d updated, %d unchanged, %d invalid\n", verb, len(report.Inserted), len(report.Updated), len(report.Unchanged), len(report.Invalid))
+}
@@ -1,55 +1,131 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	logger "github.com/lenoobz/aws-lambda-logger"
-	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
-	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
-	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
-	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
-	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
-	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
 )
 
+// dateLayout is the layout of the date flags
+const dateLayout = "2006-01-02"
+
+// command is a cli sub command
+type command struct {
+	usage string
+	run   func(args []string, log logger.ContextLog) error
+}
+
+// commands are the available sub commands, prices runs when no command is given
+var commands = map[string]command{
+	"prices": {
+		usage: "scrape latest asset prices",
+		run:   runPrices,
+	},
+	"backfill": {
+		usage: "download historical daily bars",
+		run:   runBackfill,
+	},
+	"dividends": {
+		usage: "scrape dividend and split events and update asset distributions",
+		run:   runDividends,
+	},
+	"splits": {
+		usage: "detect splits and adjust the stored price history",
+		run:   runSplits,
+	},
+	"enrich": {
+		usage: "fill missing asset metadata from yahoo and flag currency mismatches",
+		run:   runEnrich,
+	},
+	"fx": {
+		usage: "scrape the fx rates of asset currencies to the base currency",
+		run:   runFx,
+	},
+	"quarantine": {
+		usage: "list quarantined assets or clear their quarantine",
+		run:   runQuarantine,
+	},
+	"import": {
+		usage: "insert or update assets from a csv or json file",
+		run:   runImport,
+	},
+	"checkpoint": {
+		usage: "show, reset or set the checkpoint a job pages through the assets with",
+		run:   runCheckpoint,
+	},
+	"search": {
+		usage: "search yahoo symbols and optionally insert one as a new asset",
+		run:   runSearch,
+	},
+}
+
 func main() {
-	appConf := config.AppConf
+	name := "prices"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage()
+		os.Exit(2)
+	}
+
+	// the command has closed what it opened by the time it fails
+	if err := runCommand(cmd, args); err != nil {
+		log.Fatalf("%s failed: %v", name, err)
+	}
+}
 
+// runCommand runs a sub command with the app logger
+func runCommand(cmd command, args []string) error {
 	// create new logger
 	zap, err := logger.NewZapLogger()
 	if err != nil {
-		log.Fatal("create app logger failed")
+		return fmt.Errorf("create app logger failed: %w", err)
 	}
 	defer zap.Close()
 
-	// create new repository
-	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
-	if err != nil {
-		log.Fatal("create asset price mongo failed")
+	return cmd.run(args, zap)
+}
+
+// printUsage prints the available sub commands
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n\ncommands:\n", os.Args[0])
+	var names []string
+	for name := range commands {
+		names = append(names, name)
 	}
-	defer assetPriceRepo.Close()
+	sort.Strings(names)
 
-	// create new repository
-	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
-	if err != nil {
-		log.Fatal("create asset mongo failed")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].usage)
 	}
-	defer assetRepo.Close()
+}
 
-	// create new repository
-	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
-	if err != nil {
-		log.Fatal("create checkpoint mongo failed")
+// parseDate parses a date flag, an empty value returns the fallback
+func parseDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
 	}
-	defer checkpointRepo.Close()
 
-	// create new services
-	checkpointService := checkpoint.NewService(checkpointRepo, zap)
-	assetService := assets.NewService(assetRepo, *checkpointService, zap)
-	priceService := price.NewService(assetPriceRepo, zap)
+	return time.Parse(dateLayout, value)
+}
+
+// splitList splits a comma separated flag value
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
 
-	job := scraper.NewAssetPriceScraper(assetService, priceService, zap)
-	// job.ScrapeAssetPricesFromCheckpoint(consts.PAGE_SIZE)
-	job.ScrapeAllAssetPrices()
-	defer job.Close()
+	return items
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/deadletter"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/fx"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/retries"
+)
+
+// runPrices scrapes the latest asset prices
+func runPrices(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("prices", flag.ExitOnError)
+	fromCheckpoint := flags.Bool("checkpoint", false, "scrape the next page of assets from the checkpoint instead of all assets")
+	extendedHours := flags.Bool("extended-hours", false, "only store pre-market and after-hours prices of quotes outside regular hours")
+	tickers := flags.String("tickers", "", "comma separated tickers to scrape, all assets when empty")
+	types := flags.String("types", "", "comma separated asset types to scrape")
+	classes := flags.String("classes", "", "comma separated asset classes to scrape")
+	currencies := flags.String("currencies", "", "comma separated currencies to scrape")
+	includeDisabled := flags.Bool("include-disabled", false, "also scrape disabled assets")
+	includeDeleted := flags.Bool("include-deleted", false, "also scrape deleted assets")
+	flags.Parse(args)
+
+	assetFilter := &entities.AssetFilter{
+		IncludeDisabled: *includeDisabled,
+		IncludeDeleted:  *includeDeleted,
+		Types:           splitList(*types),
+		AssetClasses:    splitList(*classes),
+		Currencies:      splitList(*currencies),
+		Tickers:         splitList(*tickers),
+	}
+
+	appConf := config.AppConf
+
+	// create new repository
+	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset price mongo failed: %w", err)
+	}
+	defer assetPriceRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new repository
+	retryRepo, err := repos.NewScrapeRetryMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create scrape retry mongo failed: %w", err)
+	}
+	defer retryRepo.Close()
+
+	// create new repository
+	failureRepo, err := repos.NewScrapeFailureMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create scrape failure mongo failed: %w", err)
+	}
+	defer failureRepo.Close()
+
+	// create new repository
+	eventRepo, err := repos.NewAssetEventMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset event mongo failed: %w", err)
+	}
+	defer eventRepo.Close()
+
+	// create new repository
+	fxRateRepo, err := repos.NewFxRateMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create fx rate mongo failed: %w", err)
+	}
+	defer fxRateRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	priceService := price.NewService(assetPriceRepo, zap)
+	retryService := retries.NewService(retryRepo, zap)
+	failureService := deadletter.NewService(failureRepo, assetService, appConf.Scraper.QuarantineThreshold, zap)
+	eventService := events.NewService(eventRepo, zap)
+	fxService := fx.NewService(fxRateRepo, appConf.Scraper.BaseCurrency, appConf.Scraper.MaxFxRateAgeMS, zap)
+
+	// create new price source
+	priceSource, err := scraper.NewPriceSource(&appConf.Scraper, zap)
+	if err != nil {
+		return fmt.Errorf("create price source failed: %w", err)
+	}
+
+	job := scraper.NewAssetPriceScraper(priceSource, assetService, priceService, retryService, failureService, eventService, fxService, &appConf.Scraper, zap)
+	job.SetExtendedHoursOnly(*extendedHours)
+	job.SetAssetFilter(assetFilter)
+	if *fromCheckpoint {
+		job.ScrapeAssetPricesFromCheckpoint(consts.PAGE_SIZE)
+	} else {
+		job.ScrapeAllAssetPrices()
+	}
+	defer job.Close()
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/deadletter"
+)
+
+// runQuarantine lists the quarantined assets or clears the quarantine of some tickers
+func runQuarantine(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("quarantine", flag.ExitOnError)
+	clearFlag := flags.String("clear", "", "comma separated tickers to clear the quarantine of, lists the quarantined assets when empty")
+	flags.Parse(args)
+
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new repository
+	failureRepo, err := repos.NewScrapeFailureMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create scrape failure mongo failed: %w", err)
+	}
+	defer failureRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	failureService := deadletter.NewService(failureRepo, assetService, appConf.Scraper.QuarantineThreshold, zap)
+
+	if tickers := splitList(*clearFlag); len(tickers) > 0 {
+		numCleared, err := failureService.ClearQuarantine(ctx, tickers)
+		if err != nil {
+			return fmt.Errorf("clear quarantine failed: %w", err)
+		}
+
+		fmt.Printf("cleared the quarantine of %d assets\n", numCleared)
+		return nil
+	}
+
+	quarantined, err := assetService.GetQuarantinedAssets(ctx)
+	if err != nil {
+		return fmt.Errorf("get quarantined assets failed: %w", err)
+	}
+
+	for _, asset := range quarantined {
+		quarantinedAt := time.Unix(asset.QuarantinedAt, 0).UTC().Format(time.RFC3339)
+		fmt.Printf("%-12s %s %s\n", asset.Ticker, quarantinedAt, asset.QuarantineReason)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/symbols"
+)
+
+// runSearch searches yahoo for symbols and optionally inserts one of them as a new asset
+func runSearch(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	insert := flags.String("insert", "", "found symbol to insert as a new asset")
+	ticker := flags.String("ticker", "", "ticker of the inserted asset, the symbol when empty")
+	flags.Parse(args)
+
+	query := strings.Join(flags.Args(), " ")
+	if query == "" {
+		fmt.Fprintf(os.Stderr, "usage: %s search [flags] <name, symbol or isin>\n", os.Args[0])
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	appConf := config.AppConf
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new symbol source
+	searchSource, err := scraper.NewYahooSearchSource(&appConf.Scraper, zap)
+	if err != nil {
+		return fmt.Errorf("create search source failed: %w", err)
+	}
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	symbolService := symbols.NewService(searchSource, assetService, zap)
+
+	candidates, err := symbolService.SearchSymbols(ctx, query)
+	if err != nil {
+		return fmt.Errorf("search symbols failed: %w", err)
+	}
+
+	for _, candidate := range candidates {
+		fmt.Printf("%-14s %-10s %-14s %-4s %s\n", candidate.Symbol, candidate.ExchangeName, candidate.Type, candidate.Currency, candidate.Name)
+	}
+
+	if *insert == "" {
+		return nil
+	}
+
+	for _, candidate := range candidates {
+		if !strings.EqualFold(candidate.Symbol, *insert) {
+			continue
+		}
+
+		asset, err := symbolService.AddSymbol(ctx, candidate, *ticker)
+		if err != nil {
+			return fmt.Errorf("insert symbol failed: %w", err)
+		}
+
+		fmt.Printf("inserted asset %s for symbol %s\n", asset.Ticker, asset.YahooSymbol)
+		return nil
+	}
+
+	return fmt.Errorf("symbol %s not found in the search results", *insert)
+}
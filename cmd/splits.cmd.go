@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/repos"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/scraper"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/bars"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/corpactions"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+// runSplits detects splits and adjusts the stored price history
+func runSplits(args []string, zap logger.ContextLog) error {
+	flags := flag.NewFlagSet("splits", flag.ExitOnError)
+	fromFlag := flags.String("from", "", "first day of price history to detect splits in (YYYY-MM-DD, defaults to 30 days ago)")
+	tickersFlag := flags.String("tickers", "", "comma separated tickers to adjust, defaults to all assets")
+	flags.Parse(args)
+
+	from, err := parseDate(*fromFlag, time.Now().UTC().AddDate(0, 0, -30))
+	if err != nil {
+		return fmt.Errorf("invalid -from date: %w", err)
+	}
+
+	appConf := config.AppConf
+
+	// create new repository
+	eventRepo, err := repos.NewAssetEventMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset event mongo failed: %w", err)
+	}
+	defer eventRepo.Close()
+
+	// create new repository
+	assetPriceRepo, err := repos.NewAssetPriceMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset price mongo failed: %w", err)
+	}
+	defer assetPriceRepo.Close()
+
+	// create new repository
+	barRepo, err := repos.NewPriceBarMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create price bar mongo failed: %w", err)
+	}
+	defer barRepo.Close()
+
+	// create new repository
+	assetRepo, err := repos.NewAssetMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create asset mongo failed: %w", err)
+	}
+	defer assetRepo.Close()
+
+	// create new repository
+	checkpointRepo, err := repos.NewCheckpointMongo(nil, zap, &appConf.Mongo)
+	if err != nil {
+		return fmt.Errorf("create checkpoint mongo failed: %w", err)
+	}
+	defer checkpointRepo.Close()
+
+	// create new services
+	checkpointService := checkpoint.NewService(checkpointRepo, appConf.Scraper.CheckpointLeaseMS, zap)
+	assetService := assets.NewService(assetRepo, *checkpointService, zap)
+	priceService := price.NewService(assetPriceRepo, zap)
+	eventService := events.NewService(eventRepo, zap)
+	barService := bars.NewService(barRepo, zap)
+	corpActionService := corpactions.NewService(priceService, eventService, barService, zap)
+
+	job := scraper.NewSplitAdjuster(assetService, corpActionService, zap)
+	job.AdjustSplits(from, splitList(*tickersFlag))
+	defer job.Close()
+
+	return nil
+}
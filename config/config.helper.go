@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 )
 
 // AllowDomain const
@@ -14,3 +16,29 @@ const DomainGlob = "*yahoo.*"
 func GetPriceByTickerURL(ticker string) string {
 	return fmt.Sprintf("https://ca.finance.yahoo.com/quote/%s", ticker)
 }
+
+// YahooCookieURL const
+const YahooCookieURL = "https://fc.yahoo.com"
+
+// YahooQueryURL const
+const YahooQueryURL = "https://query1.finance.yahoo.com"
+
+// GetCrumbURL get crumb url
+func GetCrumbURL(baseURL string) string {
+	return fmt.Sprintf("%s/v1/test/getcrumb", baseURL)
+}
+
+// GetQuotesURL get batch quote url
+func GetQuotesURL(baseURL string, symbols []string, crumb string) string {
+	return fmt.Sprintf("%s/v7/finance/quote?symbols=%s&crumb=%s", baseURL, url.QueryEscape(strings.Join(symbols, ",")), url.QueryEscape(crumb))
+}
+
+// GetChartURL get daily chart url of a symbol between two unix timestamps
+func GetChartURL(baseURL string, symbol string, period1 int64, period2 int64) string {
+	return fmt.Sprintf("%s/v8/finance/chart/%s?period1=%d&period2=%d&interval=1d&events=div,split&includeAdjustedClose=true", baseURL, url.PathEscape(symbol), period1, period2)
+}
+
+// GetSearchURL get symbol search url of a query
+func GetSearchURL(baseURL string, query string, count int) string {
+	return fmt.Sprintf("%s/v1/finance/search?q=%s&quotesCount=%d&newsCount=0&listsCount=0", baseURL, url.QueryEscape(query), count)
+}
@@ -14,7 +14,25 @@ type MongoConfig struct {
 	Colnames      map[string]string
 }
 
+// ScraperConfig struct
+type ScraperConfig struct {
+	PriceSource         string
+	QuoteBatchSize      int
+	TimeoutMS           uint64
+	MaxRetries          int
+	RetryBaseDelayMS    uint64
+	RetryMaxDelayMS     uint64
+	QuarantineThreshold int64
+	// CheckpointLeaseMS is how long a run holds its page of the checkpoint before another run may reclaim it
+	CheckpointLeaseMS uint64
+	// BaseCurrency is the currency prices are converted to, and the quote currency of the scraped fx rates
+	BaseCurrency string
+	// MaxFxRateAgeMS is how old a stored fx rate may be and still convert prices, zero never expires them
+	MaxFxRateAgeMS uint64
+}
+
 // AppConfig struct
 type AppConfig struct {
-	Mongo MongoConfig
+	Mongo   MongoConfig
+	Scraper ScraperConfig
 }
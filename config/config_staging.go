@@ -21,9 +21,27 @@ var AppConf = AppConfig{
 		Dbname:        "povi",
 		SchemaVersion: "1",
 		Colnames: map[string]string{
-			"assets":            "assets",
-			"asset_prices":      "asset_prices",
-			"scrape_checkpoint": "scrape_checkpoint",
+			"assets":              "assets",
+			"asset_prices":        "asset_prices",
+			"asset_price_history": "asset_price_history",
+			"asset_price_bars":    "asset_price_bars",
+			"asset_events":        "asset_events",
+			"scrape_checkpoint":   "scrape_checkpoint",
+			"scrape_retries":      "scrape_retries",
+			"scrape_failures":     "scrape_failures",
+			"fx_rates":            "fx_rates",
 		},
 	},
+	Scraper: ScraperConfig{
+		PriceSource:         "yahoo-quote",
+		QuoteBatchSize:      50,
+		TimeoutMS:           30000,
+		MaxRetries:          3,
+		RetryBaseDelayMS:    500,
+		RetryMaxDelayMS:     8000,
+		QuarantineThreshold: 10,
+		CheckpointLeaseMS:   900000,
+		BaseCurrency:        "CAD",
+		MaxFxRateAgeMS:      345600000,
+	},
 }
@@ -2,9 +2,94 @@ package consts
 
 // Collection names
 const (
-	ASSETS_COLLECTION            = "assets"
-	ASSET_PRICES_COLLECTION      = "asset_prices"
-	SCRAPE_CHECKPOINT_COLLECTION = "scrape_checkpoint"
+	ASSETS_COLLECTION              = "assets"
+	ASSET_PRICES_COLLECTION        = "asset_prices"
+	ASSET_PRICE_HISTORY_COLLECTION = "asset_price_history"
+	ASSET_PRICE_BARS_COLLECTION    = "asset_price_bars"
+	ASSET_EVENTS_COLLECTION        = "asset_events"
+	SCRAPE_CHECKPOINT_COLLECTION   = "scrape_checkpoint"
+	SCRAPE_RETRIES_COLLECTION      = "scrape_retries"
+	SCRAPE_FAILURES_COLLECTION     = "scrape_failures"
+	FX_RATES_COLLECTION            = "fx_rates"
+)
+
+// Descriptive asset fields, named like the json fields of the asset entity
+const (
+	ASSET_NAME_FIELD             = "name"
+	ASSET_TYPE_FIELD             = "type"
+	ASSET_CLASS_FIELD            = "assetClass"
+	ASSET_CURRENCY_FIELD         = "currency"
+	ASSET_EXCHANGE_FIELD         = "exchange"
+	ASSET_YAHOO_SYMBOL_FIELD     = "yahooSymbol"
+	ASSET_ALLOCATION_STOCK_FIELD = "allocationStock"
+	ASSET_ALLOCATION_BOND_FIELD  = "allocationBond"
+	ASSET_ALLOCATION_CASH_FIELD  = "allocationCash"
+)
+
+// Checkpoint names, one per job paging through the assets
+const (
+	PRICES_CHECKPOINT    = "prices"
+	DIVIDENDS_CHECKPOINT = "dividends"
+	BACKFILL_CHECKPOINT  = "backfill"
+	ENRICH_CHECKPOINT    = "enrich"
+)
+
+// Price sources
+const (
+	YAHOO_HTML_SOURCE  = "yahoo-html"
+	YAHOO_QUOTE_SOURCE = "yahoo-quote"
+	YAHOO_CHART_SOURCE = "yahoo-chart"
+)
+
+// Asset event types
+const (
+	DIVIDEND_EVENT             = "dividend"
+	SPLIT_EVENT                = "split"
+	CURRENCY_DISCREPANCY_EVENT = "currency-discrepancy"
+)
+
+// Split event sources other than the price sources
+const (
+	RATIO_JUMP_SOURCE = "ratio-jump"
+)
+
+// Quote types
+const (
+	EQUITY_QUOTE_TYPE      = "EQUITY"
+	ETF_QUOTE_TYPE         = "ETF"
+	MUTUAL_FUND_QUOTE_TYPE = "MUTUALFUND"
+	INDEX_QUOTE_TYPE       = "INDEX"
+	CRYPTO_QUOTE_TYPE      = "CRYPTOCURRENCY"
+	FUTURE_QUOTE_TYPE      = "FUTURE"
+	CURRENCY_QUOTE_TYPE    = "CURRENCY"
+)
+
+// Dividend schedules
+const (
+	MONTHLY_SCHEDULE       = "Monthly"
+	QUARTERLY_SCHEDULE     = "Quarterly"
+	SEMI_ANNUALLY_SCHEDULE = "SemiAnnually"
+	ANNUALLY_SCHEDULE      = "Annually"
+)
+
+// Market states
+const (
+	MARKET_STATE_PRE     = "PRE"
+	MARKET_STATE_REGULAR = "REGULAR"
+	MARKET_STATE_POST    = "POST"
+	MARKET_STATE_CLOSED  = "CLOSED"
+)
+
+// Scrape error classes
+const (
+	NETWORK_ERROR   = "network"
+	HTTP_ERROR      = "http"
+	AUTH_ERROR      = "auth"
+	NOT_FOUND_ERROR = "not-found"
+	LOOKUP_ERROR    = "lookup"
+	RENAMED_ERROR   = "renamed"
+	PARSE_ERROR     = "parse"
+	STORE_ERROR     = "store"
 )
 
 const PAGE_SIZE = 100
@@ -0,0 +1,10 @@
+package entities
+
+// AssetDistribution struct holds the distribution figures computed from dividend events.
+// Yields are percentages of the latest price
+type AssetDistribution struct {
+	DividendSchedule string  `json:"dividendSchedule,omitempty"`
+	Yield12Month     float64 `json:"yield12Month,omitempty"`
+	DistYield        float64 `json:"distYield,omitempty"`
+	DistAmount       float64 `json:"distAmount,omitempty"`
+}
@@ -0,0 +1,28 @@
+package entities
+
+// AssetEvent struct is a dividend, split or currency discrepancy event of an asset. A currency
+// discrepancy holds the currency the source reported and the currency of the asset
+type AssetEvent struct {
+	Ticker           string  `json:"ticker,omitempty"`
+	Type             string  `json:"type,omitempty"`
+	Date             int64   `json:"date,omitempty"`
+	Amount           float64 `json:"amount,omitempty"`
+	Numerator        float64 `json:"numerator,omitempty"`
+	Denominator      float64 `json:"denominator,omitempty"`
+	SplitRatio       string  `json:"splitRatio,omitempty"`
+	Currency         string  `json:"currency,omitempty"`
+	ExpectedCurrency string  `json:"expectedCurrency,omitempty"`
+	Source           string  `json:"source,omitempty"`
+	AdjFactor        float64 `json:"adjFactor,omitempty"`
+	AppliedAt        int64   `json:"appliedAt,omitempty"`
+}
+
+// SplitFactor returns the factor prices quoted before the split are multiplied by,
+// e.g. 0.5 for a 2:1 split. Zero when the event is not a valid split
+func (e *AssetEvent) SplitFactor() float64 {
+	if e.Numerator <= 0 || e.Denominator <= 0 {
+		return 0
+	}
+
+	return e.Denominator / e.Numerator
+}
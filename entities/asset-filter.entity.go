@@ -0,0 +1,13 @@
+package entities
+
+// AssetFilter struct selects the assets to scrape. Disabled, deleted and quarantined assets are left
+// out unless included, empty lists match any value
+type AssetFilter struct {
+	IncludeDisabled    bool     `json:"includeDisabled,omitempty"`
+	IncludeDeleted     bool     `json:"includeDeleted,omitempty"`
+	IncludeQuarantined bool     `json:"includeQuarantined,omitempty"`
+	Types              []string `json:"types,omitempty"`
+	AssetClasses       []string `json:"assetClasses,omitempty"`
+	Currencies         []string `json:"currencies,omitempty"`
+	Tickers            []string `json:"tickers,omitempty"`
+}
@@ -0,0 +1,39 @@
+package entities
+
+// AssetImportReport struct holds the outcome of an asset import, rows are numbered from one in
+// the order of the imported file
+type AssetImportReport struct {
+	DryRun    bool                `json:"dryRun,omitempty"`
+	Inserted  []*AssetChange      `json:"inserted,omitempty"`
+	Updated   []*AssetChange      `json:"updated,omitempty"`
+	Unchanged []string            `json:"unchanged,omitempty"`
+	Invalid   []*AssetImportError `json:"invalid,omitempty"`
+}
+
+// ImportedAsset struct holds an asset read from an asset file and the descriptive fields the file
+// provides for it, only those are imported
+type ImportedAsset struct {
+	Asset  *Asset   `json:"asset,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// AssetChange struct holds the fields of an asset an import sets
+type AssetChange struct {
+	Row    int                 `json:"row,omitempty"`
+	Ticker string              `json:"ticker,omitempty"`
+	Fields []*AssetFieldChange `json:"fields,omitempty"`
+}
+
+// AssetFieldChange struct holds the stored and imported values of an asset field
+type AssetFieldChange struct {
+	Field string `json:"field,omitempty"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// AssetImportError struct holds why an imported row is invalid
+type AssetImportError struct {
+	Row    int    `json:"row,omitempty"`
+	Ticker string `json:"ticker,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
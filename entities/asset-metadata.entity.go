@@ -0,0 +1,11 @@
+package entities
+
+// AssetMetadata struct holds the descriptive fields a source reports for an asset
+type AssetMetadata struct {
+	Ticker   string `json:"ticker,omitempty"`
+	Symbol   string `json:"symbol,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Exchange string `json:"exchange,omitempty"`
+}
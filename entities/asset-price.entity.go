@@ -1,8 +1,59 @@
 package entities
 
+import "time"
+
 // AssetPrice struct
 type AssetPrice struct {
-	Ticker   string  `json:"ticker,omitempty"`
-	Price    float64 `json:"price,omitempty"`
-	Currency string  `json:"currency,omitempty"`
+	Ticker                  string  `json:"ticker,omitempty"`
+	Price                   float64 `json:"price,omitempty"`
+	AdjPrice                float64 `json:"adjPrice,omitempty"`
+	AdjFactor               float64 `json:"adjFactor,omitempty"`
+	Currency                string  `json:"currency,omitempty"`
+	PriceInBase             float64 `json:"priceInBase,omitempty"`
+	BaseCurrency            string  `json:"baseCurrency,omitempty"`
+	Exchange                string  `json:"exchange,omitempty"`
+	MarketTime              int64   `json:"marketTime,omitempty"`
+	MarketState             string  `json:"marketState,omitempty"`
+	ExchangeTimezone        string  `json:"exchangeTimezone,omitempty"`
+	PreviousClose           float64 `json:"previousClose,omitempty"`
+	Open                    float64 `json:"open,omitempty"`
+	DayHigh                 float64 `json:"dayHigh,omitempty"`
+	DayLow                  float64 `json:"dayLow,omitempty"`
+	FiftyTwoWeekHigh        float64 `json:"fiftyTwoWeekHigh,omitempty"`
+	FiftyTwoWeekLow         float64 `json:"fiftyTwoWeekLow,omitempty"`
+	Volume                  int64   `json:"volume,omitempty"`
+	AverageVolume           int64   `json:"averageVolume,omitempty"`
+	MarketCap               int64   `json:"marketCap,omitempty"`
+	Bid                     float64 `json:"bid,omitempty"`
+	Ask                     float64 `json:"ask,omitempty"`
+	Change                  float64 `json:"change,omitempty"`
+	ChangePercent           float64 `json:"changePercent,omitempty"`
+	PreMarketPrice          float64 `json:"preMarketPrice,omitempty"`
+	PreMarketChange         float64 `json:"preMarketChange,omitempty"`
+	PreMarketChangePercent  float64 `json:"preMarketChangePercent,omitempty"`
+	PreMarketTime           int64   `json:"preMarketTime,omitempty"`
+	PostMarketPrice         float64 `json:"postMarketPrice,omitempty"`
+	PostMarketChange        float64 `json:"postMarketChange,omitempty"`
+	PostMarketChangePercent float64 `json:"postMarketChangePercent,omitempty"`
+	PostMarketTime          int64   `json:"postMarketTime,omitempty"`
+	Source                  string  `json:"source,omitempty"`
+	Extractor               string  `json:"extractor,omitempty"`
+	ObservedAt              int64   `json:"observedAt,omitempty"`
+	// NavDate is the day (midnight UTC) the net asset value of a mutual fund was computed for
+	NavDate int64 `json:"navDate,omitempty"`
+}
+
+// IsStale reports whether the exchange quoted the price longer than maxAge before now.
+// A price without a market time is always considered stale
+func (p *AssetPrice) IsStale(now time.Time, maxAge time.Duration) bool {
+	if p.MarketTime == 0 {
+		return true
+	}
+
+	return now.Sub(time.Unix(p.MarketTime, 0)) > maxAge
+}
+
+// HasExtendedHoursPrice reports whether the quote has a pre-market or after-hours price
+func (p *AssetPrice) HasExtendedHoursPrice() bool {
+	return p.PreMarketPrice != 0 || p.PostMarketPrice != 0
 }
@@ -2,6 +2,8 @@ package entities
 
 // Asset struct
 type Asset struct {
+	Enabled          bool    `json:"enabled,omitempty"`
+	Deleted          bool    `json:"deleted,omitempty"`
 	Ticker           string  `json:"ticker,omitempty"`
 	Name             string  `json:"name,omitempty"`
 	Type             string  `json:"type,omitempty"`
@@ -14,4 +16,18 @@ type Asset struct {
 	Yield12Month     float64 `json:"yield12Month,omitempty"`
 	DistYield        float64 `json:"distYield,omitempty"`
 	DistAmount       float64 `json:"distAmount,omitempty"`
+	Quarantined      bool    `json:"quarantined,omitempty"`
+	QuarantinedAt    int64   `json:"quarantinedAt,omitempty"`
+	QuarantineReason string  `json:"quarantineReason,omitempty"`
+	// SuspectedDelisted is set when yahoo no longer knows the symbol or redirects it to a successor
+	SuspectedDelisted   bool   `json:"suspectedDelisted,omitempty"`
+	SuspectedDelistedAt int64  `json:"suspectedDelistedAt,omitempty"`
+	SuccessorTicker     string `json:"successorTicker,omitempty"`
+	// Exchange is the exchange the asset is listed on, it picks the suffix of the yahoo symbol
+	Exchange string `json:"exchange,omitempty"`
+	// YahooSymbol overrides the yahoo symbol mapped from the ticker and the exchange
+	YahooSymbol string `json:"yahooSymbol,omitempty"`
+	// CurrencyMismatch is set when the currency yahoo reports differs from the asset currency
+	CurrencyMismatch bool   `json:"currencyMismatch,omitempty"`
+	ReportedCurrency string `json:"reportedCurrency,omitempty"`
 }
@@ -2,6 +2,46 @@ package entities
 
 // Checkpoint struct
 type Checkpoint struct {
-	PageSize  int64 `json:"size,omitempty"`
-	PageIndex int64 `json:"index,omitempty"`
+	// Name is the job the checkpoint pages through the assets for
+	Name      string `json:"name,omitempty"`
+	PageSize  int64  `json:"size,omitempty"`
+	PageIndex int64  `json:"index,omitempty"`
+	// Owner is the run holding the lease of the page until LeaseExpiresAt
+	Owner          string `json:"owner,omitempty"`
+	LeaseExpiresAt int64  `json:"leaseExpiresAt,omitempty"`
+	// Leases and CompletedPages are only read back by GetCheckpoint
+	Leases         []*CheckpointLease `json:"leases,omitempty"`
+	CompletedPages []*CheckpointPage  `json:"completedPages,omitempty"`
+	ModifiedAt     int64              `json:"modifiedAt,omitempty"`
+}
+
+// CheckpointLease struct, a page leased to the run processing it
+type CheckpointLease struct {
+	PageIndex int64  `json:"index"`
+	PageSize  int64  `json:"size"`
+	Owner     string `json:"owner"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// CheckpointPage struct, when a page was last completed
+type CheckpointPage struct {
+	PageIndex   int64 `json:"index"`
+	CompletedAt int64 `json:"completedAt"`
+}
+
+// CheckpointStatus struct, how far a checkpoint is through the assets
+type CheckpointStatus struct {
+	Name       string      `json:"name"`
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+	NumAssets  int64       `json:"numAssets"`
+	NumPages   int64       `json:"numPages"`
+	// NextPageIndex is the page the next run leases, unless it reclaims an expired lease
+	NextPageIndex   int64   `json:"nextPageIndex"`
+	ProcessedAssets int64   `json:"processedAssets"`
+	Progress        float64 `json:"progress"`
+	// PageInterval is the average number of seconds between two completed pages, zero until two
+	// pages are completed. CycleDuration and RemainingDuration are estimated from it
+	PageInterval      int64 `json:"pageInterval,omitempty"`
+	CycleDuration     int64 `json:"cycleDuration,omitempty"`
+	RemainingDuration int64 `json:"remainingDuration,omitempty"`
 }
@@ -0,0 +1,12 @@
+package entities
+
+// FxRate struct is the latest rate of a currency pair, Rate units of To buy one unit of From
+type FxRate struct {
+	Pair       string  `json:"pair,omitempty"`
+	From       string  `json:"from,omitempty"`
+	To         string  `json:"to,omitempty"`
+	Rate       float64 `json:"rate,omitempty"`
+	MarketTime int64   `json:"marketTime,omitempty"`
+	Source     string  `json:"source,omitempty"`
+	ObservedAt int64   `json:"observedAt,omitempty"`
+}
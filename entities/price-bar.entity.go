@@ -0,0 +1,17 @@
+package entities
+
+// PriceBar struct
+type PriceBar struct {
+	Ticker    string  `json:"ticker,omitempty"`
+	Date      int64   `json:"date,omitempty"`
+	Currency  string  `json:"currency,omitempty"`
+	Open      float64 `json:"open,omitempty"`
+	High      float64 `json:"high,omitempty"`
+	Low       float64 `json:"low,omitempty"`
+	Close     float64 `json:"close,omitempty"`
+	AdjClose  float64 `json:"adjClose,omitempty"`
+	AdjFactor float64 `json:"adjFactor,omitempty"`
+	Volume    int64   `json:"volume,omitempty"`
+	Source    string  `json:"source,omitempty"`
+	FetchedAt int64   `json:"fetchedAt,omitempty"`
+}
@@ -0,0 +1,14 @@
+package entities
+
+// ScrapeFailure struct is the last scrape failure of a ticker and how many runs in a row it failed
+type ScrapeFailure struct {
+	Ticker              string `json:"ticker,omitempty"`
+	ErrorClass          string `json:"errorClass,omitempty"`
+	HTTPStatus          int    `json:"httpStatus,omitempty"`
+	SnippetHash         string `json:"snippetHash,omitempty"`
+	Successor           string `json:"successor,omitempty"`
+	LastError           string `json:"lastError,omitempty"`
+	ConsecutiveFailures int64  `json:"consecutiveFailures,omitempty"`
+	FirstFailedAt       int64  `json:"firstFailedAt,omitempty"`
+	LastFailedAt        int64  `json:"lastFailedAt,omitempty"`
+}
@@ -0,0 +1,9 @@
+package entities
+
+// ScrapeRetry struct is a ticker that still failed after the retries of a run
+type ScrapeRetry struct {
+	Ticker    string `json:"ticker,omitempty"`
+	Attempts  int64  `json:"attempts,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+	FailedAt  int64  `json:"failedAt,omitempty"`
+}
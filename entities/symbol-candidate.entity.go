@@ -0,0 +1,11 @@
+package entities
+
+// SymbolCandidate struct holds a symbol found by a symbol search
+type SymbolCandidate struct {
+	Symbol       string `json:"symbol,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Exchange     string `json:"exchange,omitempty"`
+	ExchangeName string `json:"exchangeName,omitempty"`
+	Type         string `json:"type,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+}
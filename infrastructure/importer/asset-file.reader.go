@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// Asset file formats
+const (
+	CSV_FORMAT  = "csv"
+	JSON_FORMAT = "json"
+)
+
+// assetColumns sets the asset field of a csv column, columns are named like the json fields of the
+// asset entity
+var assetColumns = map[string]func(asset *entities.Asset, value string) error{
+	tickerColumn: func(asset *entities.Asset, value string) error {
+		asset.Ticker = value
+		return nil
+	},
+	consts.ASSET_NAME_FIELD: func(asset *entities.Asset, value string) error {
+		asset.Name = value
+		return nil
+	},
+	consts.ASSET_TYPE_FIELD: func(asset *entities.Asset, value string) error {
+		asset.Type = value
+		return nil
+	},
+	consts.ASSET_CLASS_FIELD: func(asset *entities.Asset, value string) error {
+		asset.AssetClass = value
+		return nil
+	},
+	consts.ASSET_CURRENCY_FIELD: func(asset *entities.Asset, value string) error {
+		asset.Currency = value
+		return nil
+	},
+	consts.ASSET_EXCHANGE_FIELD: func(asset *entities.Asset, value string) error {
+		asset.Exchange = value
+		return nil
+	},
+	consts.ASSET_YAHOO_SYMBOL_FIELD: func(asset *entities.Asset, value string) error {
+		asset.YahooSymbol = value
+		return nil
+	},
+	consts.ASSET_ALLOCATION_STOCK_FIELD: func(asset *entities.Asset, value string) error {
+		return parseFloat(value, &asset.AllocationStock)
+	},
+	consts.ASSET_ALLOCATION_BOND_FIELD: func(asset *entities.Asset, value string) error {
+		return parseFloat(value, &asset.AllocationBond)
+	},
+	consts.ASSET_ALLOCATION_CASH_FIELD: func(asset *entities.Asset, value string) error {
+		return parseFloat(value, &asset.AllocationCash)
+	},
+}
+
+// tickerColumn is the column of the asset ticker, it identifies the asset and is not a field
+const tickerColumn = "ticker"
+
+// columnField returns the asset field a csv column or json key is named after, columns match
+// regardless of case, spaces, dashes and underscores
+func columnField(column string) (string, bool) {
+	key := strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(column)))
+	for field := range assetColumns {
+		if strings.ToLower(field) == key {
+			return field, true
+		}
+	}
+
+	return "", false
+}
+
+// ReadAssetFile reads the assets of a csv or json file, the format is taken from the extension
+// when it is empty
+func ReadAssetFile(path string, format string) ([]*entities.ImportedAsset, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadAssets(file, format)
+}
+
+// ReadAssets reads the assets of a csv file with a header row, or of a json array of assets. The
+// fields of an asset are the columns of the csv file or the keys of the json object
+func ReadAssets(r io.Reader, format string) ([]*entities.ImportedAsset, error) {
+	switch strings.ToLower(format) {
+	case CSV_FORMAT:
+		return readCSVAssets(r)
+	case JSON_FORMAT:
+		return readJSONAssets(r)
+	default:
+		return nil, fmt.Errorf("unsupported asset file format %q", format)
+	}
+}
+
+// readCSVAssets reads the assets of a csv file, the header row names the column of each field
+func readCSVAssets(r io.Reader) ([]*entities.ImportedAsset, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	setters := make([]func(asset *entities.Asset, value string) error, len(header))
+	for i, column := range header {
+		field, ok := columnField(column)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		setters[i] = assetColumns[field]
+
+		if field != tickerColumn {
+			fields = append(fields, field)
+		}
+	}
+
+	var assets []*entities.ImportedAsset
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		asset := &entities.Asset{}
+		for i, value := range record {
+			if err := setters[i](asset, strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("row %d column %q: %v", len(assets)+1, header[i], err)
+			}
+		}
+
+		assets = append(assets, &entities.ImportedAsset{
+			Asset:  asset,
+			Fields: fields,
+		})
+	}
+
+	return assets, nil
+}
+
+// readJSONAssets reads a json array of assets, the keys of each object are the fields it provides
+func readJSONAssets(r io.Reader) ([]*entities.ImportedAsset, error) {
+	var objects []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, err
+	}
+
+	var assets []*entities.ImportedAsset
+	for i, object := range objects {
+		asset := &entities.Asset{}
+		if err := json.Unmarshal(object, asset); err != nil {
+			return nil, fmt.Errorf("asset %d: %v", i+1, err)
+		}
+
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(object, &keys); err != nil {
+			return nil, fmt.Errorf("asset %d: %v", i+1, err)
+		}
+
+		var fields []string
+		for key := range keys {
+			if field, ok := columnField(key); ok && field != tickerColumn {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+
+		assets = append(assets, &entities.ImportedAsset{
+			Asset:  asset,
+			Fields: fields,
+		})
+	}
+
+	return assets, nil
+}
+
+// parseFloat parses a number column, an empty value is zero
+func parseFloat(value string, field *float64) error {
+	if value == "" {
+		*field = 0
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return err
+	}
+
+	*field = f
+	return nil
+}
@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+)
+
+func TestReadCSVAssetsFields(t *testing.T) {
+	csv := "Ticker,Name,allocation_stock\nXIU,iShares S&P/TSX 60,100\nVFV,,\n"
+
+	assets, err := ReadAssets(strings.NewReader(csv), CSV_FORMAT)
+	if err != nil {
+		t.Fatalf("ReadAssets() error = %v", err)
+	}
+
+	if len(assets) != 2 {
+		t.Fatalf("ReadAssets() = %d assets, want 2", len(assets))
+	}
+
+	// every row provides the columns of the header, empty values included
+	want := []string{consts.ASSET_NAME_FIELD, consts.ASSET_ALLOCATION_STOCK_FIELD}
+	for _, asset := range assets {
+		if !reflect.DeepEqual(asset.Fields, want) {
+			t.Errorf("%s fields = %v, want %v", asset.Asset.Ticker, asset.Fields, want)
+		}
+	}
+
+	if assets[0].Asset.AllocationStock != 100 || assets[1].Asset.Name != "" {
+		t.Errorf("assets = %+v %+v, want XIU at 100 and VFV without name", assets[0].Asset, assets[1].Asset)
+	}
+
+	if _, err := ReadAssets(strings.NewReader("ticker,price\nXIU,31\n"), CSV_FORMAT); err == nil {
+		t.Errorf("ReadAssets() of an unknown column, want an error")
+	}
+}
+
+func TestReadJSONAssetsFields(t *testing.T) {
+	json := `[{"ticker": "XIU", "currency": "CAD", "yahooSymbol": "XIU.TO"}, {"ticker": "VFV"}]`
+
+	assets, err := ReadAssets(strings.NewReader(json), JSON_FORMAT)
+	if err != nil {
+		t.Fatalf("ReadAssets() error = %v", err)
+	}
+
+	if len(assets) != 2 {
+		t.Fatalf("ReadAssets() = %d assets, want 2", len(assets))
+	}
+
+	want := []string{consts.ASSET_CURRENCY_FIELD, consts.ASSET_YAHOO_SYMBOL_FIELD}
+	if !reflect.DeepEqual(assets[0].Fields, want) {
+		t.Errorf("XIU fields = %v, want %v", assets[0].Fields, want)
+	}
+
+	if assets[0].Asset.YahooSymbol != "XIU.TO" {
+		t.Errorf("XIU yahoo symbol = %q, want XIU.TO", assets[0].Asset.YahooSymbol)
+	}
+
+	if len(assets[1].Fields) != 0 {
+		t.Errorf("VFV fields = %v, want none", assets[1].Fields)
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AssetEventModel struct
+type AssetEventModel struct {
+	ID               *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt        int64               `bson:"createdAt,omitempty"`
+	ModifiedAt       int64               `bson:"modifiedAt,omitempty"`
+	Schema           string              `bson:"schema,omitempty"`
+	Source           string              `bson:"source,omitempty"`
+	Ticker           string              `bson:"ticker,omitempty"`
+	Type             string              `bson:"type,omitempty"`
+	Date             int64               `bson:"date"`
+	Amount           float64             `bson:"amount,omitempty"`
+	Numerator        float64             `bson:"numerator,omitempty"`
+	Denominator      float64             `bson:"denominator,omitempty"`
+	SplitRatio       string              `bson:"splitRatio,omitempty"`
+	Currency         string              `bson:"currency,omitempty"`
+	ExpectedCurrency string              `bson:"expectedCurrency,omitempty"`
+	AdjFactor        float64             `bson:"adjFactor,omitempty"`
+	AppliedAt        int64               `bson:"appliedAt,omitempty"`
+}
+
+// NewAssetEventModel create asset event model
+func NewAssetEventModel(ctx context.Context, log logger.ContextLog, event *entities.AssetEvent, schemaVersion string) (*AssetEventModel, error) {
+	return &AssetEventModel{
+		ModifiedAt:       time.Now().UTC().Unix(),
+		Schema:           schemaVersion,
+		Source:           event.Source,
+		Ticker:           event.Ticker,
+		Type:             event.Type,
+		Date:             event.Date,
+		Amount:           event.Amount,
+		Numerator:        event.Numerator,
+		Denominator:      event.Denominator,
+		SplitRatio:       event.SplitRatio,
+		Currency:         event.Currency,
+		ExpectedCurrency: event.ExpectedCurrency,
+		AdjFactor:        event.AdjFactor,
+		AppliedAt:        event.AppliedAt,
+	}, nil
+}
+
+// ToEntity converts asset event model to asset event entity
+func (m *AssetEventModel) ToEntity() *entities.AssetEvent {
+	return &entities.AssetEvent{
+		Ticker:           m.Ticker,
+		Type:             m.Type,
+		Date:             m.Date,
+		Amount:           m.Amount,
+		Numerator:        m.Numerator,
+		Denominator:      m.Denominator,
+		SplitRatio:       m.SplitRatio,
+		Currency:         m.Currency,
+		ExpectedCurrency: m.ExpectedCurrency,
+		Source:           m.Source,
+		AdjFactor:        m.AdjFactor,
+		AppliedAt:        m.AppliedAt,
+	}
+}
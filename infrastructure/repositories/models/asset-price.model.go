@@ -11,27 +11,163 @@ import (
 
 // AssetPriceModel struct
 type AssetPriceModel struct {
-	ID         *primitive.ObjectID `bson:"_id,omitempty"`
-	CreatedAt  int64               `bson:"createdAt,omitempty"`
-	ModifiedAt int64               `bson:"modifiedAt,omitempty"`
-	Enabled    bool                `bson:"enabled"`
-	Deleted    bool                `bson:"deleted"`
-	Schema     string              `bson:"schema,omitempty"`
-	Source     string              `bson:"source,omitempty"`
-	Ticker     string              `bson:"ticker,omitempty"`
-	Currency   string              `bson:"currency,omitempty"`
-	Price      float64             `bson:"price,omitempty"`
+	ID                      *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt               int64               `bson:"createdAt,omitempty"`
+	ModifiedAt              int64               `bson:"modifiedAt,omitempty"`
+	Enabled                 bool                `bson:"enabled"`
+	Deleted                 bool                `bson:"deleted"`
+	Schema                  string              `bson:"schema,omitempty"`
+	Source                  string              `bson:"source,omitempty"`
+	Extractor               string              `bson:"extractor,omitempty"`
+	Ticker                  string              `bson:"ticker,omitempty"`
+	Currency                string              `bson:"currency,omitempty"`
+	PriceInBase             float64             `bson:"priceInBase,omitempty"`
+	BaseCurrency            string              `bson:"baseCurrency,omitempty"`
+	Price                   float64             `bson:"price,omitempty"`
+	Exchange                string              `bson:"exchange,omitempty"`
+	MarketTime              int64               `bson:"marketTime,omitempty"`
+	MarketState             string              `bson:"marketState,omitempty"`
+	ExchangeTimezone        string              `bson:"exchangeTimezone,omitempty"`
+	PreviousClose           float64             `bson:"previousClose,omitempty"`
+	Open                    float64             `bson:"open,omitempty"`
+	DayHigh                 float64             `bson:"dayHigh,omitempty"`
+	DayLow                  float64             `bson:"dayLow,omitempty"`
+	FiftyTwoWeekHigh        float64             `bson:"fiftyTwoWeekHigh,omitempty"`
+	FiftyTwoWeekLow         float64             `bson:"fiftyTwoWeekLow,omitempty"`
+	Volume                  int64               `bson:"volume,omitempty"`
+	AverageVolume           int64               `bson:"averageVolume,omitempty"`
+	MarketCap               int64               `bson:"marketCap,omitempty"`
+	Bid                     float64             `bson:"bid,omitempty"`
+	Ask                     float64             `bson:"ask,omitempty"`
+	Change                  float64             `bson:"change,omitempty"`
+	ChangePercent           float64             `bson:"changePercent,omitempty"`
+	PreMarketPrice          float64             `bson:"preMarketPrice,omitempty"`
+	PreMarketChange         float64             `bson:"preMarketChange,omitempty"`
+	PreMarketChangePercent  float64             `bson:"preMarketChangePercent,omitempty"`
+	PreMarketTime           int64               `bson:"preMarketTime,omitempty"`
+	PostMarketPrice         float64             `bson:"postMarketPrice,omitempty"`
+	PostMarketChange        float64             `bson:"postMarketChange,omitempty"`
+	PostMarketChangePercent float64             `bson:"postMarketChangePercent,omitempty"`
+	PostMarketTime          int64               `bson:"postMarketTime,omitempty"`
+	ObservedAt              int64               `bson:"observedAt,omitempty"`
+	NavDate                 int64               `bson:"navDate,omitempty"`
 }
 
 // NewAssetPriceModel create asset price model
 func NewAssetPriceModel(ctx context.Context, log logger.ContextLog, assetPrice *entities.AssetPrice, schemaVersion string) (*AssetPriceModel, error) {
 	return &AssetPriceModel{
-		ModifiedAt: time.Now().UTC().Unix(),
-		Enabled:    true,
-		Deleted:    false,
-		Schema:     schemaVersion,
-		Ticker:     assetPrice.Ticker,
-		Currency:   assetPrice.Currency,
-		Price:      assetPrice.Price,
+		ModifiedAt:              time.Now().UTC().Unix(),
+		Enabled:                 true,
+		Deleted:                 false,
+		Schema:                  schemaVersion,
+		Source:                  assetPrice.Source,
+		Extractor:               assetPrice.Extractor,
+		Ticker:                  assetPrice.Ticker,
+		Currency:                assetPrice.Currency,
+		PriceInBase:             assetPrice.PriceInBase,
+		BaseCurrency:            assetPrice.BaseCurrency,
+		Price:                   assetPrice.Price,
+		Exchange:                assetPrice.Exchange,
+		MarketTime:              assetPrice.MarketTime,
+		MarketState:             assetPrice.MarketState,
+		ExchangeTimezone:        assetPrice.ExchangeTimezone,
+		PreviousClose:           assetPrice.PreviousClose,
+		Open:                    assetPrice.Open,
+		DayHigh:                 assetPrice.DayHigh,
+		DayLow:                  assetPrice.DayLow,
+		FiftyTwoWeekHigh:        assetPrice.FiftyTwoWeekHigh,
+		FiftyTwoWeekLow:         assetPrice.FiftyTwoWeekLow,
+		Volume:                  assetPrice.Volume,
+		AverageVolume:           assetPrice.AverageVolume,
+		MarketCap:               assetPrice.MarketCap,
+		Bid:                     assetPrice.Bid,
+		Ask:                     assetPrice.Ask,
+		Change:                  assetPrice.Change,
+		ChangePercent:           assetPrice.ChangePercent,
+		PreMarketPrice:          assetPrice.PreMarketPrice,
+		PreMarketChange:         assetPrice.PreMarketChange,
+		PreMarketChangePercent:  assetPrice.PreMarketChangePercent,
+		PreMarketTime:           assetPrice.PreMarketTime,
+		PostMarketPrice:         assetPrice.PostMarketPrice,
+		PostMarketChange:        assetPrice.PostMarketChange,
+		PostMarketChangePercent: assetPrice.PostMarketChangePercent,
+		PostMarketTime:          assetPrice.PostMarketTime,
+		ObservedAt:              assetPrice.ObservedAt,
+		NavDate:                 assetPrice.NavDate,
+	}, nil
+}
+
+// ToEntity converts asset price model to asset price entity
+func (m *AssetPriceModel) ToEntity() *entities.AssetPrice {
+	return &entities.AssetPrice{
+		Ticker:                  m.Ticker,
+		Price:                   m.Price,
+		Currency:                m.Currency,
+		PriceInBase:             m.PriceInBase,
+		BaseCurrency:            m.BaseCurrency,
+		Exchange:                m.Exchange,
+		MarketTime:              m.MarketTime,
+		MarketState:             m.MarketState,
+		ExchangeTimezone:        m.ExchangeTimezone,
+		PreviousClose:           m.PreviousClose,
+		Open:                    m.Open,
+		DayHigh:                 m.DayHigh,
+		DayLow:                  m.DayLow,
+		FiftyTwoWeekHigh:        m.FiftyTwoWeekHigh,
+		FiftyTwoWeekLow:         m.FiftyTwoWeekLow,
+		Volume:                  m.Volume,
+		AverageVolume:           m.AverageVolume,
+		MarketCap:               m.MarketCap,
+		Bid:                     m.Bid,
+		Ask:                     m.Ask,
+		Change:                  m.Change,
+		ChangePercent:           m.ChangePercent,
+		PreMarketPrice:          m.PreMarketPrice,
+		PreMarketChange:         m.PreMarketChange,
+		PreMarketChangePercent:  m.PreMarketChangePercent,
+		PreMarketTime:           m.PreMarketTime,
+		PostMarketPrice:         m.PostMarketPrice,
+		PostMarketChange:        m.PostMarketChange,
+		PostMarketChangePercent: m.PostMarketChangePercent,
+		PostMarketTime:          m.PostMarketTime,
+		Source:                  m.Source,
+		Extractor:               m.Extractor,
+		ObservedAt:              m.ObservedAt,
+		NavDate:                 m.NavDate,
+	}
+}
+
+// AssetExtendedHoursPriceModel struct holds the fields of the asset price document
+// updated by an extended hours scrape, leaving the regular market price untouched
+type AssetExtendedHoursPriceModel struct {
+	ModifiedAt              int64   `bson:"modifiedAt,omitempty"`
+	Schema                  string  `bson:"schema,omitempty"`
+	Ticker                  string  `bson:"ticker,omitempty"`
+	MarketState             string  `bson:"marketState,omitempty"`
+	PreMarketPrice          float64 `bson:"preMarketPrice,omitempty"`
+	PreMarketChange         float64 `bson:"preMarketChange,omitempty"`
+	PreMarketChangePercent  float64 `bson:"preMarketChangePercent,omitempty"`
+	PreMarketTime           int64   `bson:"preMarketTime,omitempty"`
+	PostMarketPrice         float64 `bson:"postMarketPrice,omitempty"`
+	PostMarketChange        float64 `bson:"postMarketChange,omitempty"`
+	PostMarketChangePercent float64 `bson:"postMarketChangePercent,omitempty"`
+	PostMarketTime          int64   `bson:"postMarketTime,omitempty"`
+}
+
+// NewAssetExtendedHoursPriceModel create asset extended hours price model
+func NewAssetExtendedHoursPriceModel(ctx context.Context, log logger.ContextLog, assetPrice *entities.AssetPrice, schemaVersion string) (*AssetExtendedHoursPriceModel, error) {
+	return &AssetExtendedHoursPriceModel{
+		ModifiedAt:              time.Now().UTC().Unix(),
+		Schema:                  schemaVersion,
+		Ticker:                  assetPrice.Ticker,
+		MarketState:             assetPrice.MarketState,
+		PreMarketPrice:          assetPrice.PreMarketPrice,
+		PreMarketChange:         assetPrice.PreMarketChange,
+		PreMarketChangePercent:  assetPrice.PreMarketChangePercent,
+		PreMarketTime:           assetPrice.PreMarketTime,
+		PostMarketPrice:         assetPrice.PostMarketPrice,
+		PostMarketChange:        assetPrice.PostMarketChange,
+		PostMarketChangePercent: assetPrice.PostMarketChangePercent,
+		PostMarketTime:          assetPrice.PostMarketTime,
 	}, nil
 }
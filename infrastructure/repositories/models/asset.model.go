@@ -0,0 +1,202 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AssetModel struct
+type AssetModel struct {
+	ID                  *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt           int64               `bson:"createdAt,omitempty"`
+	ModifiedAt          int64               `bson:"modifiedAt,omitempty"`
+	Enabled             bool                `bson:"enabled"`
+	Deleted             bool                `bson:"deleted"`
+	Schema              string              `bson:"schema,omitempty"`
+	Ticker              string              `bson:"ticker,omitempty"`
+	Name                string              `bson:"name,omitempty"`
+	Type                string              `bson:"type,omitempty"`
+	AssetClass          string              `bson:"assetClass,omitempty"`
+	Currency            string              `bson:"currency,omitempty"`
+	Exchange            string              `bson:"exchange,omitempty"`
+	YahooSymbol         string              `bson:"yahooSymbol,omitempty"`
+	AllocationStock     float64             `bson:"allocationStock,omitempty"`
+	AllocationBond      float64             `bson:"allocationBond,omitempty"`
+	AllocationCash      float64             `bson:"allocationCash,omitempty"`
+	DividendSchedule    string              `bson:"dividendSchedule,omitempty"`
+	Yield12Month        float64             `bson:"yield12Month,omitempty"`
+	DistYield           float64             `bson:"distYield,omitempty"`
+	DistAmount          float64             `bson:"distAmount,omitempty"`
+	Quarantined         bool                `bson:"quarantined,omitempty"`
+	QuarantinedAt       int64               `bson:"quarantinedAt,omitempty"`
+	QuarantineReason    string              `bson:"quarantineReason,omitempty"`
+	SuspectedDelisted   bool                `bson:"suspectedDelisted,omitempty"`
+	SuspectedDelistedAt int64               `bson:"suspectedDelistedAt,omitempty"`
+	SuccessorTicker     string              `bson:"successorTicker,omitempty"`
+	CurrencyMismatch    bool                `bson:"currencyMismatch,omitempty"`
+	ReportedCurrency    string              `bson:"reportedCurrency,omitempty"`
+}
+
+// NewAssetModel create asset model
+func NewAssetModel(ctx context.Context, log logger.ContextLog, asset *entities.Asset, schemaVersion string) (*AssetModel, error) {
+	now := time.Now().UTC().Unix()
+
+	return &AssetModel{
+		CreatedAt:        now,
+		ModifiedAt:       now,
+		Enabled:          true,
+		Deleted:          false,
+		Schema:           schemaVersion,
+		Ticker:           strings.ToUpper(asset.Ticker),
+		Name:             asset.Name,
+		Type:             asset.Type,
+		AssetClass:       asset.AssetClass,
+		Currency:         strings.ToUpper(asset.Currency),
+		Exchange:         strings.ToUpper(asset.Exchange),
+		YahooSymbol:      strings.ToUpper(asset.YahooSymbol),
+		AllocationStock:  asset.AllocationStock,
+		AllocationBond:   asset.AllocationBond,
+		AllocationCash:   asset.AllocationCash,
+		DividendSchedule: asset.DividendSchedule,
+		Yield12Month:     asset.Yield12Month,
+		DistYield:        asset.DistYield,
+		DistAmount:       asset.DistAmount,
+	}, nil
+}
+
+// ToEntity converts asset model to asset entity
+func (m *AssetModel) ToEntity() *entities.Asset {
+	return &entities.Asset{
+		Enabled:             m.Enabled,
+		Deleted:             m.Deleted,
+		Ticker:              m.Ticker,
+		Name:                m.Name,
+		Type:                m.Type,
+		AssetClass:          m.AssetClass,
+		Currency:            m.Currency,
+		Exchange:            m.Exchange,
+		YahooSymbol:         m.YahooSymbol,
+		AllocationStock:     m.AllocationStock,
+		AllocationBond:      m.AllocationBond,
+		AllocationCash:      m.AllocationCash,
+		DividendSchedule:    m.DividendSchedule,
+		Yield12Month:        m.Yield12Month,
+		DistYield:           m.DistYield,
+		DistAmount:          m.DistAmount,
+		Quarantined:         m.Quarantined,
+		QuarantinedAt:       m.QuarantinedAt,
+		QuarantineReason:    m.QuarantineReason,
+		SuspectedDelisted:   m.SuspectedDelisted,
+		SuspectedDelistedAt: m.SuspectedDelistedAt,
+		SuccessorTicker:     m.SuccessorTicker,
+		CurrencyMismatch:    m.CurrencyMismatch,
+		ReportedCurrency:    m.ReportedCurrency,
+	}
+}
+
+// AssetInfoModel struct holds the descriptive fields of the asset document set by an update, fields
+// the update does not give are nil and left as they are
+type AssetInfoModel struct {
+	ModifiedAt      int64    `bson:"modifiedAt,omitempty"`
+	Schema          string   `bson:"schema,omitempty"`
+	Name            *string  `bson:"name,omitempty"`
+	Type            *string  `bson:"type,omitempty"`
+	AssetClass      *string  `bson:"assetClass,omitempty"`
+	Currency        *string  `bson:"currency,omitempty"`
+	Exchange        *string  `bson:"exchange,omitempty"`
+	YahooSymbol     *string  `bson:"yahooSymbol,omitempty"`
+	AllocationStock *float64 `bson:"allocationStock,omitempty"`
+	AllocationBond  *float64 `bson:"allocationBond,omitempty"`
+	AllocationCash  *float64 `bson:"allocationCash,omitempty"`
+}
+
+// NewAssetInfoModel create asset info model of the given fields of an asset
+func NewAssetInfoModel(ctx context.Context, log logger.ContextLog, asset *entities.Asset, fields []string, schemaVersion string) (*AssetInfoModel, error) {
+	info := &AssetInfoModel{
+		ModifiedAt: time.Now().UTC().Unix(),
+		Schema:     schemaVersion,
+	}
+
+	for _, field := range fields {
+		switch field {
+		case consts.ASSET_NAME_FIELD:
+			info.Name = &asset.Name
+		case consts.ASSET_TYPE_FIELD:
+			info.Type = &asset.Type
+		case consts.ASSET_CLASS_FIELD:
+			info.AssetClass = &asset.AssetClass
+		case consts.ASSET_CURRENCY_FIELD:
+			currency := strings.ToUpper(asset.Currency)
+			info.Currency = &currency
+		case consts.ASSET_EXCHANGE_FIELD:
+			exchange := strings.ToUpper(asset.Exchange)
+			info.Exchange = &exchange
+		case consts.ASSET_YAHOO_SYMBOL_FIELD:
+			yahooSymbol := strings.ToUpper(asset.YahooSymbol)
+			info.YahooSymbol = &yahooSymbol
+		case consts.ASSET_ALLOCATION_STOCK_FIELD:
+			info.AllocationStock = &asset.AllocationStock
+		case consts.ASSET_ALLOCATION_BOND_FIELD:
+			info.AllocationBond = &asset.AllocationBond
+		case consts.ASSET_ALLOCATION_CASH_FIELD:
+			info.AllocationCash = &asset.AllocationCash
+		default:
+			log.Error(ctx, "unknown asset field", "field", field)
+			return nil, fmt.Errorf("unknown asset field %q", field)
+		}
+	}
+
+	return info, nil
+}
+
+// AssetMetadataModel struct holds the fields of the asset document set by the enrichment job, empty
+// fields are left as they are. The currency mismatch is flagged when a reported currency is given
+type AssetMetadataModel struct {
+	ModifiedAt       int64  `bson:"modifiedAt,omitempty"`
+	Name             string `bson:"name,omitempty"`
+	Type             string `bson:"type,omitempty"`
+	Currency         string `bson:"currency,omitempty"`
+	Exchange         string `bson:"exchange,omitempty"`
+	CurrencyMismatch bool   `bson:"currencyMismatch"`
+	ReportedCurrency string `bson:"reportedCurrency"`
+}
+
+// NewAssetMetadataModel create asset metadata model
+func NewAssetMetadataModel(ctx context.Context, log logger.ContextLog, metadata *entities.AssetMetadata, reportedCurrency string) (*AssetMetadataModel, error) {
+	return &AssetMetadataModel{
+		ModifiedAt:       time.Now().UTC().Unix(),
+		Name:             metadata.Name,
+		Type:             metadata.Type,
+		Currency:         strings.ToUpper(metadata.Currency),
+		Exchange:         strings.ToUpper(metadata.Exchange),
+		CurrencyMismatch: reportedCurrency != "",
+		ReportedCurrency: strings.ToUpper(reportedCurrency),
+	}, nil
+}
+
+// AssetDistributionModel struct holds the distribution fields of the asset document
+type AssetDistributionModel struct {
+	ModifiedAt       int64   `bson:"modifiedAt,omitempty"`
+	DividendSchedule string  `bson:"dividendSchedule"`
+	Yield12Month     float64 `bson:"yield12Month"`
+	DistYield        float64 `bson:"distYield"`
+	DistAmount       float64 `bson:"distAmount"`
+}
+
+// NewAssetDistributionModel create asset distribution model
+func NewAssetDistributionModel(ctx context.Context, log logger.ContextLog, distribution *entities.AssetDistribution) (*AssetDistributionModel, error) {
+	return &AssetDistributionModel{
+		ModifiedAt:       time.Now().UTC().Unix(),
+		DividendSchedule: distribution.DividendSchedule,
+		Yield12Month:     distribution.Yield12Month,
+		DistYield:        distribution.DistYield,
+		DistAmount:       distribution.DistAmount,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNewAssetInfoModelSetsGivenFields(t *testing.T) {
+	zap, err := logger.NewZapLogger()
+	if err != nil {
+		t.Fatalf("NewZapLogger() error = %v", err)
+	}
+	defer zap.Close()
+
+	asset := &entities.Asset{Ticker: "AAA", Name: "Triple A", Currency: "usd", AllocationStock: 60}
+
+	// a given field without value is set, it clears the stored one
+	info, err := NewAssetInfoModel(context.Background(), zap, asset, []string{consts.ASSET_CURRENCY_FIELD, consts.ASSET_EXCHANGE_FIELD}, "1")
+	if err != nil {
+		t.Fatalf("NewAssetInfoModel() error = %v", err)
+	}
+
+	raw, err := bson.Marshal(info)
+	if err != nil {
+		t.Fatalf("bson.Marshal() error = %v", err)
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("bson.Unmarshal() error = %v", err)
+	}
+
+	want := bson.M{"currency": "USD", "exchange": ""}
+	for key, value := range want {
+		if doc[key] != value {
+			t.Errorf("%s = %v, want %v", key, doc[key], value)
+		}
+	}
+
+	for _, key := range []string{"name", "type", "assetClass", "yahooSymbol", "allocationStock", "allocationBond", "allocationCash"} {
+		if _, ok := doc[key]; ok {
+			t.Errorf("%s is set, want it left out", key)
+		}
+	}
+
+	if _, err := NewAssetInfoModel(context.Background(), zap, asset, []string{"ticker"}, "1"); err == nil {
+		t.Errorf("NewAssetInfoModel() of the ticker, want an error")
+	}
+}
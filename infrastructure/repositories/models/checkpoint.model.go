@@ -1,38 +1,82 @@
 package models
 
 import (
-	"context"
-	"time"
-
-	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// CheckPointModel struct
+// CheckPointModel struct, the cursor of a job paging through the assets
 type CheckPointModel struct {
-	ID              *primitive.ObjectID   `bson:"_id,omitempty"`
-	CreatedAt       int64                 `bson:"createdAt,omitempty"`
-	ModifiedAt      int64                 `bson:"modifiedAt,omitempty"`
-	Enabled         bool                  `bson:"enabled"`
-	Deleted         bool                  `bson:"deleted"`
-	Schema          string                `bson:"schema,omitempty"`
-	PriceCheckPoint *PriceCheckPointModel `bson:"priceCheckPoint,omitempty"`
+	ID             *primitive.ObjectID     `bson:"_id,omitempty"`
+	CreatedAt      int64                   `bson:"createdAt,omitempty"`
+	ModifiedAt     int64                   `bson:"modifiedAt,omitempty"`
+	Enabled        bool                    `bson:"enabled"`
+	Deleted        bool                    `bson:"deleted"`
+	Schema         string                  `bson:"schema,omitempty"`
+	Name           string                  `bson:"name"`
+	PageSize       int64                   `bson:"size,omitempty"`
+	PrevIndex      int64                   `bson:"prevIndex"`
+	Leases         []*CheckPointLeaseModel `bson:"leases,omitempty"`
+	CompletedPages []*CheckPointPageModel  `bson:"completedPages,omitempty"`
+}
+
+// CheckPointLeaseModel struct, a page leased to the run processing it
+type CheckPointLeaseModel struct {
+	PageIndex int64  `bson:"index"`
+	PageSize  int64  `bson:"size"`
+	Owner     string `bson:"owner"`
+	ExpiresAt int64  `bson:"expiresAt"`
+}
+
+// CheckPointPageModel struct, when a page was last completed
+type CheckPointPageModel struct {
+	PageIndex   int64 `bson:"index"`
+	CompletedAt int64 `bson:"completedAt"`
 }
 
-type PriceCheckPointModel struct {
-	PageSize  int64 `bson:"size,omitempty"`
-	PrevIndex int64 `bson:"prevIndex"`
+// ToEntity converts checkpoint model to checkpoint entity
+func (m *CheckPointModel) ToEntity() *entities.Checkpoint {
+	checkpoint := &entities.Checkpoint{
+		Name:       m.Name,
+		PageSize:   m.PageSize,
+		PageIndex:  m.PrevIndex,
+		ModifiedAt: m.ModifiedAt,
+	}
+
+	for _, lease := range m.Leases {
+		checkpoint.Leases = append(checkpoint.Leases, &entities.CheckpointLease{
+			PageIndex: lease.PageIndex,
+			PageSize:  lease.PageSize,
+			Owner:     lease.Owner,
+			ExpiresAt: lease.ExpiresAt,
+		})
+	}
+
+	for _, page := range m.CompletedPages {
+		checkpoint.CompletedPages = append(checkpoint.CompletedPages, &entities.CheckpointPage{
+			PageIndex:   page.PageIndex,
+			CompletedAt: page.CompletedAt,
+		})
+	}
+
+	return checkpoint
 }
 
-// NewCheckPointModel create checkpoint model
-func NewCheckPointModel(ctx context.Context, log logger.ContextLog, pageSize int64, schemaVersion string) (*CheckPointModel, error) {
-	return &CheckPointModel{
-		ModifiedAt: time.Now().UTC().Unix(),
-		Enabled:    true,
-		Deleted:    false,
-		Schema:     schemaVersion,
-		PriceCheckPoint: &PriceCheckPointModel{
-			PageSize: pageSize,
-		},
-	}, nil
+// ToLeaseEntity converts the lease of the owner to checkpoint entity, nil when the owner holds none
+func (m *CheckPointModel) ToLeaseEntity(owner string) *entities.Checkpoint {
+	for _, lease := range m.Leases {
+		if lease.Owner != owner {
+			continue
+		}
+
+		return &entities.Checkpoint{
+			Name:           m.Name,
+			PageSize:       lease.PageSize,
+			PageIndex:      lease.PageIndex,
+			Owner:          lease.Owner,
+			LeaseExpiresAt: lease.ExpiresAt,
+		}
+	}
+
+	return nil
 }
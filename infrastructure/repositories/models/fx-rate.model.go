@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FxRateModel struct
+type FxRateModel struct {
+	ID         *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt  int64               `bson:"createdAt,omitempty"`
+	ModifiedAt int64               `bson:"modifiedAt,omitempty"`
+	Schema     string              `bson:"schema,omitempty"`
+	Source     string              `bson:"source,omitempty"`
+	Pair       string              `bson:"pair,omitempty"`
+	From       string              `bson:"from,omitempty"`
+	To         string              `bson:"to,omitempty"`
+	Rate       float64             `bson:"rate,omitempty"`
+	MarketTime int64               `bson:"marketTime,omitempty"`
+	ObservedAt int64               `bson:"observedAt,omitempty"`
+}
+
+// NewFxRateModel create fx rate model
+func NewFxRateModel(ctx context.Context, log logger.ContextLog, fxRate *entities.FxRate, schemaVersion string) (*FxRateModel, error) {
+	now := time.Now().UTC().Unix()
+
+	observedAt := fxRate.ObservedAt
+	if observedAt == 0 {
+		observedAt = now
+	}
+
+	return &FxRateModel{
+		ModifiedAt: now,
+		Schema:     schemaVersion,
+		Source:     fxRate.Source,
+		Pair:       strings.ToUpper(fxRate.Pair),
+		From:       strings.ToUpper(fxRate.From),
+		To:         strings.ToUpper(fxRate.To),
+		Rate:       fxRate.Rate,
+		MarketTime: fxRate.MarketTime,
+		ObservedAt: observedAt,
+	}, nil
+}
+
+// ToEntity converts fx rate model to fx rate entity
+func (m *FxRateModel) ToEntity() *entities.FxRate {
+	return &entities.FxRate{
+		Pair:       m.Pair,
+		From:       m.From,
+		To:         m.To,
+		Rate:       m.Rate,
+		MarketTime: m.MarketTime,
+		Source:     m.Source,
+		ObservedAt: m.ObservedAt,
+	}
+}
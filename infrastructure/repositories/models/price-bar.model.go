@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PriceBarModel struct
+type PriceBarModel struct {
+	ID         *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt  int64               `bson:"createdAt,omitempty"`
+	ModifiedAt int64               `bson:"modifiedAt,omitempty"`
+	Schema     string              `bson:"schema,omitempty"`
+	Source     string              `bson:"source,omitempty"`
+	Ticker     string              `bson:"ticker,omitempty"`
+	Date       int64               `bson:"date"`
+	Currency   string              `bson:"currency,omitempty"`
+	Open       float64             `bson:"open,omitempty"`
+	High       float64             `bson:"high,omitempty"`
+	Low        float64             `bson:"low,omitempty"`
+	Close      float64             `bson:"close,omitempty"`
+	AdjClose   float64             `bson:"adjClose,omitempty"`
+	Volume     int64               `bson:"volume,omitempty"`
+	AdjFactor  float64             `bson:"adjFactor,omitempty"`
+	FetchedAt  int64               `bson:"fetchedAt,omitempty"`
+}
+
+// NewPriceBarModel create price bar model
+func NewPriceBarModel(ctx context.Context, log logger.ContextLog, bar *entities.PriceBar, schemaVersion string) (*PriceBarModel, error) {
+	// the chart quotes bars adjusted for the splits known when they are fetched, a refetched
+	// bar is not adjusted until a split after its fetch is applied to it
+	adjFactor := bar.AdjFactor
+	if adjFactor == 0 {
+		adjFactor = 1
+	}
+
+	fetchedAt := bar.FetchedAt
+	if fetchedAt == 0 {
+		fetchedAt = time.Now().UTC().Unix()
+	}
+
+	return &PriceBarModel{
+		ModifiedAt: time.Now().UTC().Unix(),
+		Schema:     schemaVersion,
+		Source:     bar.Source,
+		Ticker:     bar.Ticker,
+		Date:       bar.Date,
+		Currency:   bar.Currency,
+		Open:       bar.Open,
+		High:       bar.High,
+		Low:        bar.Low,
+		Close:      bar.Close,
+		AdjClose:   bar.AdjClose,
+		Volume:     bar.Volume,
+		AdjFactor:  adjFactor,
+		FetchedAt:  fetchedAt,
+	}, nil
+}
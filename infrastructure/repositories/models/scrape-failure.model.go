@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScrapeFailureModel struct
+type ScrapeFailureModel struct {
+	ID                  *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt           int64               `bson:"createdAt,omitempty"`
+	ModifiedAt          int64               `bson:"modifiedAt,omitempty"`
+	Schema              string              `bson:"schema,omitempty"`
+	Ticker              string              `bson:"ticker,omitempty"`
+	ErrorClass          string              `bson:"errorClass,omitempty"`
+	HTTPStatus          int                 `bson:"httpStatus"`
+	SnippetHash         string              `bson:"snippetHash"`
+	Successor           string              `bson:"successor"`
+	LastError           string              `bson:"lastError,omitempty"`
+	ConsecutiveFailures int64               `bson:"consecutiveFailures,omitempty"`
+	FirstFailedAt       int64               `bson:"firstFailedAt,omitempty"`
+	LastFailedAt        int64               `bson:"lastFailedAt,omitempty"`
+}
+
+// NewScrapeFailureModel create scrape failure model
+func NewScrapeFailureModel(ctx context.Context, log logger.ContextLog, failure *entities.ScrapeFailure, schemaVersion string) (*ScrapeFailureModel, error) {
+	return &ScrapeFailureModel{
+		ModifiedAt:   time.Now().UTC().Unix(),
+		Schema:       schemaVersion,
+		Ticker:       failure.Ticker,
+		ErrorClass:   failure.ErrorClass,
+		HTTPStatus:   failure.HTTPStatus,
+		SnippetHash:  failure.SnippetHash,
+		Successor:    failure.Successor,
+		LastError:    failure.LastError,
+		LastFailedAt: failure.LastFailedAt,
+	}, nil
+}
+
+// ToEntity converts scrape failure model to scrape failure entity
+func (m *ScrapeFailureModel) ToEntity() *entities.ScrapeFailure {
+	return &entities.ScrapeFailure{
+		Ticker:              m.Ticker,
+		ErrorClass:          m.ErrorClass,
+		HTTPStatus:          m.HTTPStatus,
+		SnippetHash:         m.SnippetHash,
+		Successor:           m.Successor,
+		LastError:           m.LastError,
+		ConsecutiveFailures: m.ConsecutiveFailures,
+		FirstFailedAt:       m.FirstFailedAt,
+		LastFailedAt:        m.LastFailedAt,
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScrapeRetryModel struct
+type ScrapeRetryModel struct {
+	ID         *primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt  int64               `bson:"createdAt,omitempty"`
+	ModifiedAt int64               `bson:"modifiedAt,omitempty"`
+	Schema     string              `bson:"schema,omitempty"`
+	Ticker     string              `bson:"ticker,omitempty"`
+	Attempts   int64               `bson:"attempts,omitempty"`
+	LastError  string              `bson:"lastError,omitempty"`
+	FailedAt   int64               `bson:"failedAt,omitempty"`
+}
+
+// NewScrapeRetryModel create scrape retry model
+func NewScrapeRetryModel(ctx context.Context, log logger.ContextLog, retry *entities.ScrapeRetry, schemaVersion string) (*ScrapeRetryModel, error) {
+	return &ScrapeRetryModel{
+		ModifiedAt: time.Now().UTC().Unix(),
+		Schema:     schemaVersion,
+		Ticker:     retry.Ticker,
+		LastError:  retry.LastError,
+		FailedAt:   retry.FailedAt,
+	}, nil
+}
+
+// ToEntity converts scrape retry model to scrape retry entity
+func (m *ScrapeRetryModel) ToEntity() *entities.ScrapeRetry {
+	return &entities.ScrapeRetry{
+		Ticker:    m.Ticker,
+		Attempts:  m.Attempts,
+		LastError: m.LastError,
+		FailedAt:  m.FailedAt,
+	}
+}
@@ -0,0 +1,292 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AssetEventMongo struct
+type AssetEventMongo struct {
+	db     *mongo.Database
+	client *mongo.Client
+	log    logger.ContextLog
+	conf   *config.MongoConfig
+}
+
+// NewAssetEventMongo creates new asset event mongo repo
+func NewAssetEventMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*AssetEventMongo, error) {
+	if db != nil {
+		return &AssetEventMongo{
+			db:   db,
+			log:  log,
+			conf: conf,
+		}, nil
+	}
+
+	// set context with timeout from the config
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), conf.TimeoutMS)
+	defer cancel()
+
+	// set mongo client options
+	clientOptions := options.Client()
+
+	// set min pool size
+	if conf.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(conf.MinPoolSize)
+	}
+
+	// set max pool size
+	if conf.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(conf.MaxPoolSize)
+	}
+
+	// set max idle time ms
+	if conf.MaxIdleTimeMS > 0 {
+		clientOptions.SetMaxConnIdleTime(time.Duration(conf.MaxIdleTimeMS) * time.Millisecond)
+	}
+
+	// construct a connection string from mongo config object
+	cxnString := fmt.Sprintf("mongodb+srv://%s:%s@%s", conf.Username, conf.Password, conf.Host)
+
+	// create mongo client by making new connection
+	client, err := mongo.Connect(ctx, clientOptions.ApplyURI(cxnString))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssetEventMongo{
+		db:     client.Database(conf.Dbname),
+		client: client,
+		log:    log,
+		conf:   conf,
+	}, nil
+}
+
+// Close disconnect from database
+func (r *AssetEventMongo) Close() {
+	ctx := context.Background()
+	r.log.Info(ctx, "close mongo client")
+
+	if r.client == nil {
+		return
+	}
+
+	if err := r.client.Disconnect(ctx); err != nil {
+		r.log.Error(ctx, "disconnect mongo failed", "error", err)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Implement interface
+///////////////////////////////////////////////////////////////////////////////
+
+// InsertAssetEvents bulk upserts asset events keyed by ticker, type and date
+func (r *AssetEventMongo) InsertAssetEvents(ctx context.Context, events []*entities.AssetEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_EVENTS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	var writes []mongo.WriteModel
+	for _, event := range events {
+		eventModel, err := models.NewAssetEventModel(ctx, r.log, event, r.conf.SchemaVersion)
+		if err != nil {
+			r.log.Error(ctx, "create model failed", "error", err)
+			return err
+		}
+
+		filter := bson.D{
+			{
+				Key:   "ticker",
+				Value: eventModel.Ticker,
+			},
+			{
+				Key:   "type",
+				Value: eventModel.Type,
+			},
+			{
+				Key:   "date",
+				Value: eventModel.Date,
+			},
+		}
+
+		update := bson.D{
+			{
+				Key:   "$set",
+				Value: eventModel,
+			},
+			{
+				Key: "$setOnInsert",
+				Value: bson.D{{
+					Key:   "createdAt",
+					Value: time.Now().UTC().Unix(),
+				}},
+			},
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	_, err := col.BulkWrite(ctx, writes, opts)
+	if err != nil {
+		r.log.Error(ctx, "bulk write failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindAssetEvents find events of a type for a ticker between from and to (unix seconds, inclusive)
+func (r *AssetEventMongo) FindAssetEvents(ctx context.Context, ticker string, eventType string, from int64, to int64) ([]*entities.AssetEvent, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_EVENTS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: ticker,
+		},
+		{
+			Key:   "type",
+			Value: eventType,
+		},
+		{
+			Key: "date",
+			Value: bson.D{
+				{Key: "$gte", Value: from},
+				{Key: "$lte", Value: to},
+			},
+		},
+	}
+
+	// find options
+	findOptions := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+
+	cur, err := col.Find(ctx, filter, findOptions)
+
+	// only run defer function when find success
+	if cur != nil {
+		defer func() {
+			if deferErr := cur.Close(ctx); deferErr != nil {
+				err = deferErr
+			}
+		}()
+	}
+
+	// find was not succeed
+	if err != nil {
+		r.log.Error(ctx, "find query failed", "error", err)
+		return nil, err
+	}
+
+	var events []*entities.AssetEvent
+
+	// iterate over the cursor to decode document one at a time
+	for cur.Next(ctx) {
+		// decode cursor to asset event model
+		var event models.AssetEventModel
+		if err = cur.Decode(&event); err != nil {
+			r.log.Error(ctx, "decode failed", "error", err)
+			return nil, err
+		}
+
+		events = append(events, event.ToEntity())
+	}
+
+	if err := cur.Err(); err != nil {
+		r.log.Error(ctx, "iterate over cursor failed", "error", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// UpdateAssetEventApplied records when and with which adjustment factor an event was applied
+func (r *AssetEventMongo) UpdateAssetEventApplied(ctx context.Context, event *entities.AssetEvent) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_EVENTS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: event.Ticker,
+		},
+		{
+			Key:   "type",
+			Value: event.Type,
+		},
+		{
+			Key:   "date",
+			Value: event.Date,
+		},
+	}
+
+	update := bson.D{{
+		Key: "$set",
+		Value: bson.D{
+			{
+				Key:   "adjFactor",
+				Value: event.AdjFactor,
+			},
+			{
+				Key:   "appliedAt",
+				Value: event.AppliedAt,
+			},
+			{
+				Key:   "modifiedAt",
+				Value: time.Now().UTC().Unix(),
+			},
+		},
+	}}
+
+	_, err := col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -26,11 +26,13 @@ type AssetPriceMongo struct {
 // NewAssetPriceMongo creates new asset price mongo repo
 func NewAssetPriceMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*AssetPriceMongo, error) {
 	if db != nil {
-		return &AssetPriceMongo{
+		repo := &AssetPriceMongo{
 			db:   db,
 			log:  log,
 			conf: conf,
-		}, nil
+		}
+
+		return repo, repo.createIndexes()
 	}
 
 	// set context with timeout from the config
@@ -65,12 +67,40 @@ func NewAssetPriceMongo(db *mongo.Database, log logger.ContextLog, conf *config.
 		return nil, err
 	}
 
-	return &AssetPriceMongo{
+	repo := &AssetPriceMongo{
 		db:     client.Database(conf.Dbname),
 		client: client,
 		log:    log,
 		conf:   conf,
-	}, nil
+	}
+
+	return repo, repo.createIndexes()
+}
+
+// createIndexes creates the price history index, an observation is unique per ticker and
+// observation time and the history is read by ticker and time range
+func (r *AssetPriceMongo) createIndexes() error {
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), r.conf.TimeoutMS)
+	defer cancel()
+
+	keys := bson.D{
+		{
+			Key:   "ticker",
+			Value: 1,
+		},
+		{
+			Key:   "observedAt",
+			Value: 1,
+		},
+	}
+
+	if err := createIndex(ctx, r.db, r.conf.Colnames, consts.ASSET_PRICE_HISTORY_COLLECTION, keys, true); err != nil {
+		r.log.Error(ctx, "create index failed", "error", err)
+		return err
+	}
+
+	return nil
 }
 
 // Close disconnect from database
@@ -140,3 +170,306 @@ func (r *AssetPriceMongo) InsertAssetPrice(ctx context.Context, assetPrice *enti
 
 	return nil
 }
+
+// InsertAssetPriceHistory insert asset price into the price history. The observation is upserted on
+// ticker and observation time, storing it again keeps the first one
+func (r *AssetPriceMongo) InsertAssetPriceHistory(ctx context.Context, assetPrice *entities.AssetPrice) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	historyModel, err := models.NewAssetPriceHistoryModel(ctx, r.log, assetPrice, r.conf.SchemaVersion)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICE_HISTORY_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: historyModel.Ticker,
+		},
+		{
+			Key:   "observedAt",
+			Value: historyModel.ObservedAt,
+		},
+	}
+
+	// update
+	update := bson.D{
+		{
+			Key:   "$setOnInsert",
+			Value: historyModel,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+
+	_, err = col.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindAssetPriceHistory find price history of a ticker observed between from and to (unix seconds, inclusive)
+func (r *AssetPriceMongo) FindAssetPriceHistory(ctx context.Context, ticker string, from int64, to int64) ([]*entities.AssetPrice, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICE_HISTORY_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: ticker,
+		},
+		{
+			Key: "observedAt",
+			Value: bson.D{
+				{Key: "$gte", Value: from},
+				{Key: "$lte", Value: to},
+			},
+		},
+	}
+
+	// find options
+	findOptions := options.Find().SetSort(bson.D{{Key: "observedAt", Value: 1}})
+
+	cur, err := col.Find(ctx, filter, findOptions)
+
+	// only run defer function when find success
+	if cur != nil {
+		defer func() {
+			if deferErr := cur.Close(ctx); deferErr != nil {
+				err = deferErr
+			}
+		}()
+	}
+
+	// find was not succeed
+	if err != nil {
+		r.log.Error(ctx, "find query failed", "error", err)
+		return nil, err
+	}
+
+	var prices []*entities.AssetPrice
+
+	// iterate over the cursor to decode document one at a time
+	for cur.Next(ctx) {
+		// decode cursor to asset price history model
+		var history models.AssetPriceHistoryModel
+		if err = cur.Decode(&history); err != nil {
+			r.log.Error(ctx, "decode failed", "error", err)
+			return nil, err
+		}
+
+		prices = append(prices, history.ToEntity())
+	}
+
+	if err := cur.Err(); err != nil {
+		r.log.Error(ctx, "iterate over cursor failed", "error", err)
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// UpdateExtendedHoursPrice update pre-market and after-hours fields of asset price
+func (r *AssetPriceMongo) UpdateExtendedHoursPrice(ctx context.Context, assetPrice *entities.AssetPrice) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	extendedModel, err := models.NewAssetExtendedHoursPriceModel(ctx, r.log, assetPrice, r.conf.SchemaVersion)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: extendedModel.Ticker,
+	}}
+
+	update := bson.D{
+		{
+			Key:   "$set",
+			Value: extendedModel,
+		},
+		{
+			Key: "$setOnInsert",
+			Value: bson.D{
+				{
+					Key:   "createdAt",
+					Value: time.Now().UTC().Unix(),
+				},
+				{
+					Key:   "enabled",
+					Value: true,
+				},
+				{
+					Key:   "deleted",
+					Value: false,
+				},
+			},
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+
+	_, err = col.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindAssetPrice find the latest price of a ticker
+func (r *AssetPriceMongo) FindAssetPrice(ctx context.Context, ticker string) (*entities.AssetPrice, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: ticker,
+	}}
+
+	// find options
+	findOptions := options.FindOne()
+
+	var priceModel models.AssetPriceModel
+	if err := col.FindOne(ctx, filter, findOptions).Decode(&priceModel); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		r.log.Error(ctx, "find one failed", "error", err)
+		return nil, err
+	}
+
+	return priceModel.ToEntity(), nil
+}
+
+// AdjustAssetPriceHistory multiplies the adjustment factor of the price history of a ticker quoted
+// before the given time (unix seconds) by factor and recomputes the adjusted price. The unadjusted
+// price is left untouched. Observations without a market time fall back on their observed time
+func (r *AssetPriceMongo) AdjustAssetPriceHistory(ctx context.Context, ticker string, before int64, factor float64) (int64, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICE_HISTORY_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return 0, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: ticker,
+		},
+		{
+			Key: "$or",
+			Value: bson.A{
+				bson.D{{
+					Key:   "marketTime",
+					Value: bson.D{{Key: "$lt", Value: before}},
+				}},
+				bson.D{
+					{
+						Key:   "marketTime",
+						Value: bson.D{{Key: "$exists", Value: false}},
+					},
+					{
+						Key:   "observedAt",
+						Value: bson.D{{Key: "$lt", Value: before}},
+					},
+				},
+			},
+		},
+	}
+
+	// documents stored before adjustments existed have no factor yet
+	adjFactor := bson.D{{
+		Key: "$multiply",
+		Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$adjFactor", 1}}},
+			factor,
+		},
+	}}
+
+	update := mongo.Pipeline{
+		{{
+			Key: "$set",
+			Value: bson.D{
+				{
+					Key:   "adjFactor",
+					Value: adjFactor,
+				},
+				{
+					Key:   "modifiedAt",
+					Value: time.Now().UTC().Unix(),
+				},
+			},
+		}},
+		{{
+			Key: "$set",
+			Value: bson.D{{
+				Key:   "adjPrice",
+				Value: bson.D{{Key: "$multiply", Value: bson.A{"$price", "$adjFactor"}}},
+			}},
+		}},
+	}
+
+	res, err := col.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update many failed", "error", err)
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
@@ -3,12 +3,14 @@ package repos
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	logger "github.com/lenoobz/aws-lambda-logger"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -90,8 +92,101 @@ func (r *AssetMongo) Close() {
 // Implement interface
 ///////////////////////////////////////////////////////////////////////////////
 
+// newAssetFilter builds the query of an asset filter, a nil filter selects the enabled assets
+// that are neither deleted nor quarantined. Assets without the flags are enabled, not deleted
+// and not quarantined
+func newAssetFilter(filter *entities.AssetFilter) (bson.D, error) {
+	if filter == nil {
+		filter = &entities.AssetFilter{}
+	}
+
+	query := bson.D{}
+
+	if !filter.IncludeQuarantined {
+		query = append(query, bson.E{
+			Key: "quarantined",
+			Value: bson.D{{
+				Key:   "$ne",
+				Value: true,
+			}},
+		})
+	}
+
+	if !filter.IncludeDisabled {
+		query = append(query, bson.E{
+			Key: "enabled",
+			Value: bson.D{{
+				Key:   "$ne",
+				Value: false,
+			}},
+		})
+	}
+
+	if !filter.IncludeDeleted {
+		query = append(query, bson.E{
+			Key: "deleted",
+			Value: bson.D{{
+				Key:   "$ne",
+				Value: true,
+			}},
+		})
+	}
+
+	if len(filter.Types) > 0 {
+		query = append(query, bson.E{
+			Key: "type",
+			Value: bson.D{{
+				Key:   "$in",
+				Value: filter.Types,
+			}},
+		})
+	}
+
+	if len(filter.AssetClasses) > 0 {
+		query = append(query, bson.E{
+			Key: "assetClass",
+			Value: bson.D{{
+				Key:   "$in",
+				Value: filter.AssetClasses,
+			}},
+		})
+	}
+
+	if len(filter.Currencies) > 0 {
+		upperCurrencies, err := stringsToUpperCase(filter.Currencies)
+		if err != nil {
+			return nil, err
+		}
+
+		query = append(query, bson.E{
+			Key: "currency",
+			Value: bson.D{{
+				Key:   "$in",
+				Value: upperCurrencies,
+			}},
+		})
+	}
+
+	if len(filter.Tickers) > 0 {
+		upperTickers, err := stringsToUpperCase(filter.Tickers)
+		if err != nil {
+			return nil, err
+		}
+
+		query = append(query, bson.E{
+			Key: "ticker",
+			Value: bson.D{{
+				Key:   "$in",
+				Value: upperTickers,
+			}},
+		})
+	}
+
+	return query, nil
+}
+
 // CountAssets count number of assets available
-func (r *AssetMongo) CountAssets(ctx context.Context) (int64, error) {
+func (r *AssetMongo) CountAssets(ctx context.Context, assetFilter *entities.AssetFilter) (int64, error) {
 	// create new context for the query
 	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
 	defer cancel()
@@ -105,7 +200,11 @@ func (r *AssetMongo) CountAssets(ctx context.Context) (int64, error) {
 	col := r.db.Collection(colname)
 
 	// filter
-	filter := bson.D{}
+	filter, err := newAssetFilter(assetFilter)
+	if err != nil {
+		r.log.Error(ctx, "create asset filter failed", "error", err)
+		return 0, err
+	}
 
 	// find options
 	countOptions := options.Count()
@@ -114,7 +213,7 @@ func (r *AssetMongo) CountAssets(ctx context.Context) (int64, error) {
 }
 
 // FindAllAssets find all assets
-func (r *AssetMongo) FindAllAssets(ctx context.Context) ([]*entities.Asset, error) {
+func (r *AssetMongo) FindAllAssets(ctx context.Context, assetFilter *entities.AssetFilter) ([]*entities.Asset, error) {
 	// create new context for the query
 	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
 	defer cancel()
@@ -128,7 +227,11 @@ func (r *AssetMongo) FindAllAssets(ctx context.Context) ([]*entities.Asset, erro
 	col := r.db.Collection(colname)
 
 	// filter
-	filter := bson.D{}
+	filter, err := newAssetFilter(assetFilter)
+	if err != nil {
+		r.log.Error(ctx, "create asset filter failed", "error", err)
+		return nil, err
+	}
 
 	// find options
 	findOptions := options.Find()
@@ -154,14 +257,14 @@ func (r *AssetMongo) FindAllAssets(ctx context.Context) ([]*entities.Asset, erro
 
 	// iterate over the cursor to decode document one at a time
 	for cur.Next(ctx) {
-		// decode cursor to activity model
-		var asset entities.Asset
+		// decode cursor to asset model
+		var asset models.AssetModel
 		if err = cur.Decode(&asset); err != nil {
 			r.log.Error(ctx, "decode failed", "error", err)
 			return nil, err
 		}
 
-		assets = append(assets, &asset)
+		assets = append(assets, asset.ToEntity())
 	}
 
 	if err := cur.Err(); err != nil {
@@ -173,7 +276,7 @@ func (r *AssetMongo) FindAllAssets(ctx context.Context) ([]*entities.Asset, erro
 }
 
 // FindAssetsFromCheckpoint find assets from checkpoint
-func (r *AssetMongo) FindAssetsFromCheckpoint(ctx context.Context, checkpoint *entities.Checkpoint) ([]*entities.Asset, error) {
+func (r *AssetMongo) FindAssetsFromCheckpoint(ctx context.Context, checkpoint *entities.Checkpoint, assetFilter *entities.AssetFilter) ([]*entities.Asset, error) {
 	// create new context for the query
 	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
 	defer cancel()
@@ -187,7 +290,11 @@ func (r *AssetMongo) FindAssetsFromCheckpoint(ctx context.Context, checkpoint *e
 	col := r.db.Collection(colname)
 
 	// filter
-	filter := bson.D{}
+	filter, err := newAssetFilter(assetFilter)
+	if err != nil {
+		r.log.Error(ctx, "create asset filter failed", "error", err)
+		return nil, err
+	}
 
 	// find options
 	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetSkip(checkpoint.PageIndex * checkpoint.PageSize).SetLimit(checkpoint.PageSize)
@@ -213,14 +320,14 @@ func (r *AssetMongo) FindAssetsFromCheckpoint(ctx context.Context, checkpoint *e
 
 	// iterate over the cursor to decode document one at a time
 	for cur.Next(ctx) {
-		// decode cursor to activity model
-		var asset entities.Asset
+		// decode cursor to asset model
+		var asset models.AssetModel
 		if err = cur.Decode(&asset); err != nil {
 			r.log.Error(ctx, "decode failed", "error", err)
 			return nil, err
 		}
 
-		assets = append(assets, &asset)
+		assets = append(assets, asset.ToEntity())
 	}
 
 	if err := cur.Err(); err != nil {
@@ -230,3 +337,715 @@ func (r *AssetMongo) FindAssetsFromCheckpoint(ctx context.Context, checkpoint *e
 
 	return assets, nil
 }
+
+// FindAssetsByTickers find assets of the given tickers
+func (r *AssetMongo) FindAssetsByTickers(ctx context.Context, tickers []string) ([]*entities.Asset, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// no tickers select no assets rather than all of them
+	if len(tickers) == 0 {
+		return nil, nil
+	}
+
+	// filter
+	filter, err := newAssetFilter(&entities.AssetFilter{Tickers: tickers})
+	if err != nil {
+		r.log.Error(ctx, "create asset filter failed", "error", err)
+		return nil, err
+	}
+
+	// find options
+	findOptions := options.Find()
+
+	cur, err := col.Find(ctx, filter, findOptions)
+
+	// only run defer function when find success
+	if cur != nil {
+		defer func() {
+			if deferErr := cur.Close(ctx); deferErr != nil {
+				err = deferErr
+			}
+		}()
+	}
+
+	// find was not succeed
+	if err != nil {
+		r.log.Error(ctx, "find query failed", "error", err)
+		return nil, err
+	}
+
+	var assets []*entities.Asset
+
+	// iterate over the cursor to decode document one at a time
+	for cur.Next(ctx) {
+		// decode cursor to asset model
+		var asset models.AssetModel
+		if err = cur.Decode(&asset); err != nil {
+			r.log.Error(ctx, "decode failed", "error", err)
+			return nil, err
+		}
+
+		assets = append(assets, asset.ToEntity())
+	}
+
+	if err := cur.Err(); err != nil {
+		r.log.Error(ctx, "iterate over cursor failed", "error", err)
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// UpdateAssetDistribution update dividend schedule, yields and distribution amount of an asset
+func (r *AssetMongo) UpdateAssetDistribution(ctx context.Context, ticker string, distribution *entities.AssetDistribution) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	distributionModel, err := models.NewAssetDistributionModel(ctx, r.log, distribution)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: strings.ToUpper(ticker),
+	}}
+
+	update := bson.D{{
+		Key:   "$set",
+		Value: distributionModel,
+	}}
+
+	_, err = col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAssetMetadata set the non empty metadata fields of an asset, and flag the currency yahoo
+// reports when it differs from the asset currency. An empty reported currency clears the flag
+func (r *AssetMongo) UpdateAssetMetadata(ctx context.Context, ticker string, metadata *entities.AssetMetadata, reportedCurrency string) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	metadataModel, err := models.NewAssetMetadataModel(ctx, r.log, metadata, reportedCurrency)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: strings.ToUpper(ticker),
+	}}
+
+	update := bson.D{{
+		Key:   "$set",
+		Value: metadataModel,
+	}}
+
+	_, err = col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindQuarantinedAssets find the quarantined assets
+func (r *AssetMongo) FindQuarantinedAssets(ctx context.Context) ([]*entities.Asset, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{{
+		Key:   "quarantined",
+		Value: true,
+	}}
+
+	// find options
+	findOptions := options.Find().SetSort(bson.D{{Key: "quarantinedAt", Value: 1}})
+
+	cur, err := col.Find(ctx, filter, findOptions)
+
+	// only run defer function when find success
+	if cur != nil {
+		defer func() {
+			if deferErr := cur.Close(ctx); deferErr != nil {
+				err = deferErr
+			}
+		}()
+	}
+
+	// find was not succeed
+	if err != nil {
+		r.log.Error(ctx, "find query failed", "error", err)
+		return nil, err
+	}
+
+	var assets []*entities.Asset
+
+	// iterate over the cursor to decode document one at a time
+	for cur.Next(ctx) {
+		// decode cursor to asset model
+		var asset models.AssetModel
+		if err = cur.Decode(&asset); err != nil {
+			r.log.Error(ctx, "decode failed", "error", err)
+			return nil, err
+		}
+
+		assets = append(assets, asset.ToEntity())
+	}
+
+	if err := cur.Err(); err != nil {
+		r.log.Error(ctx, "iterate over cursor failed", "error", err)
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// QuarantineAsset quarantine an asset so the asset queries skip it
+func (r *AssetMongo) QuarantineAsset(ctx context.Context, ticker string, reason string) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	now := time.Now().UTC().Unix()
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: strings.ToUpper(ticker),
+	}}
+
+	update := bson.D{{
+		Key: "$set",
+		Value: bson.D{
+			{
+				Key:   "quarantined",
+				Value: true,
+			},
+			{
+				Key:   "quarantinedAt",
+				Value: now,
+			},
+			{
+				Key:   "quarantineReason",
+				Value: reason,
+			},
+			{
+				Key:   "modifiedAt",
+				Value: now,
+			},
+		},
+	}}
+
+	_, err := col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ClearAssetQuarantine clear the quarantine of the given tickers, returns the number of cleared assets
+func (r *AssetMongo) ClearAssetQuarantine(ctx context.Context, tickers []string) (int64, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return 0, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	upperTickers, err := stringsToUpperCase(tickers)
+	if err != nil {
+		r.log.Error(ctx, "convert tickers to upper case failed", "error", err)
+		return 0, err
+	}
+
+	filter := bson.D{
+		{
+			Key: "ticker",
+			Value: bson.D{{
+				Key:   "$in",
+				Value: upperTickers,
+			}},
+		},
+		{
+			Key:   "quarantined",
+			Value: true,
+		},
+	}
+
+	update := bson.D{
+		{
+			Key: "$unset",
+			Value: bson.D{
+				{
+					Key:   "quarantined",
+					Value: "",
+				},
+				{
+					Key:   "quarantinedAt",
+					Value: "",
+				},
+				{
+					Key:   "quarantineReason",
+					Value: "",
+				},
+			},
+		},
+		{
+			Key: "$set",
+			Value: bson.D{{
+				Key:   "modifiedAt",
+				Value: time.Now().UTC().Unix(),
+			}},
+		},
+	}
+
+	res, err := col.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update many failed", "error", err)
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// FlagAssetDelisted flag an asset as suspected delisted, the successor is recorded when not empty
+func (r *AssetMongo) FlagAssetDelisted(ctx context.Context, ticker string, successor string) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	now := time.Now().UTC().Unix()
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: strings.ToUpper(ticker),
+	}}
+
+	fields := bson.D{
+		{
+			Key:   "suspectedDelisted",
+			Value: true,
+		},
+		{
+			Key:   "suspectedDelistedAt",
+			Value: now,
+		},
+		{
+			Key:   "modifiedAt",
+			Value: now,
+		},
+	}
+
+	if successor != "" {
+		fields = append(fields, bson.E{
+			Key:   "successorTicker",
+			Value: strings.ToUpper(successor),
+		})
+	}
+
+	update := bson.D{{
+		Key:   "$set",
+		Value: fields,
+	}}
+
+	_, err := col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ClearAssetDelisted clear the suspected delisted flag of the given tickers
+func (r *AssetMongo) ClearAssetDelisted(ctx context.Context, tickers []string) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	upperTickers, err := stringsToUpperCase(tickers)
+	if err != nil {
+		r.log.Error(ctx, "convert tickers to upper case failed", "error", err)
+		return err
+	}
+
+	filter := bson.D{
+		{
+			Key: "ticker",
+			Value: bson.D{{
+				Key:   "$in",
+				Value: upperTickers,
+			}},
+		},
+		{
+			Key:   "suspectedDelisted",
+			Value: true,
+		},
+	}
+
+	update := bson.D{
+		{
+			Key: "$unset",
+			Value: bson.D{
+				{
+					Key:   "suspectedDelisted",
+					Value: "",
+				},
+				{
+					Key:   "suspectedDelistedAt",
+					Value: "",
+				},
+			},
+		},
+		{
+			Key: "$set",
+			Value: bson.D{{
+				Key:   "modifiedAt",
+				Value: time.Now().UTC().Unix(),
+			}},
+		},
+	}
+
+	_, err = col.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update many failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// InsertAsset insert a new asset, fails when an asset with the same ticker already exists
+func (r *AssetMongo) InsertAsset(ctx context.Context, asset *entities.Asset) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	assetModel, err := models.NewAssetModel(ctx, r.log, asset, r.conf.SchemaVersion)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: assetModel.Ticker,
+	}}
+
+	update := bson.D{{
+		Key:   "$setOnInsert",
+		Value: assetModel,
+	}}
+
+	// the document before the update tells whether the ticker was already taken
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var existing models.AssetModel
+	err = col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+
+	if err != nil {
+		r.log.Error(ctx, "find one and update failed", "error", err)
+		return err
+	}
+
+	if existing.Deleted {
+		r.log.Error(ctx, "asset is deleted", "ticker", assetModel.Ticker)
+		return fmt.Errorf("asset %s is deleted, restore it instead", assetModel.Ticker)
+	}
+
+	r.log.Error(ctx, "asset already exists", "ticker", assetModel.Ticker)
+	return fmt.Errorf("asset %s already exists", assetModel.Ticker)
+}
+
+// UpdateAsset update the given descriptive fields of an asset that is not deleted
+func (r *AssetMongo) UpdateAsset(ctx context.Context, asset *entities.Asset, fields []string) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	infoModel, err := models.NewAssetInfoModel(ctx, r.log, asset, fields, r.conf.SchemaVersion)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: strings.ToUpper(asset.Ticker),
+		},
+		{
+			Key: "deleted",
+			Value: bson.D{{
+				Key:   "$ne",
+				Value: true,
+			}},
+		},
+	}
+
+	update := bson.D{{
+		Key:   "$set",
+		Value: infoModel,
+	}}
+
+	res, err := col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		r.log.Error(ctx, "asset not found", "ticker", asset.Ticker)
+		return fmt.Errorf("asset %s not found", strings.ToUpper(asset.Ticker))
+	}
+
+	return nil
+}
+
+// SoftDeleteAsset flag an asset as deleted and disabled, the document is kept
+func (r *AssetMongo) SoftDeleteAsset(ctx context.Context, ticker string) error {
+	return r.updateAssetFlags(ctx, ticker, bson.D{
+		{
+			Key:   "enabled",
+			Value: false,
+		},
+		{
+			Key:   "deleted",
+			Value: true,
+		},
+	})
+}
+
+// RestoreAsset clear the deleted flag of an asset and enable it again
+func (r *AssetMongo) RestoreAsset(ctx context.Context, ticker string) error {
+	return r.updateAssetFlags(ctx, ticker, bson.D{
+		{
+			Key:   "enabled",
+			Value: true,
+		},
+		{
+			Key:   "deleted",
+			Value: false,
+		},
+	})
+}
+
+// SetAssetEnabled enable or disable an asset
+func (r *AssetMongo) SetAssetEnabled(ctx context.Context, ticker string, enabled bool) error {
+	return r.updateAssetFlags(ctx, ticker, bson.D{{
+		Key:   "enabled",
+		Value: enabled,
+	}})
+}
+
+// updateAssetFlags set the given flags of an asset
+func (r *AssetMongo) updateAssetFlags(ctx context.Context, ticker string, flags bson.D) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: strings.ToUpper(ticker),
+	}}
+
+	update := bson.D{{
+		Key: "$set",
+		Value: append(flags, bson.E{
+			Key:   "modifiedAt",
+			Value: time.Now().UTC().Unix(),
+		}),
+	}}
+
+	res, err := col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		r.log.Error(ctx, "asset not found", "ticker", ticker)
+		return fmt.Errorf("asset %s not found", strings.ToUpper(ticker))
+	}
+
+	return nil
+}
+
+// UpsertAssets insert new assets and update the existing ones by ticker, only the imported fields of
+// each asset are set
+func (r *AssetMongo) UpsertAssets(ctx context.Context, assets []*entities.ImportedAsset) error {
+	if len(assets) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSETS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	var writes []mongo.WriteModel
+	for _, imported := range assets {
+		asset := imported.Asset
+		infoModel, err := models.NewAssetInfoModel(ctx, r.log, asset, imported.Fields, r.conf.SchemaVersion)
+		if err != nil {
+			r.log.Error(ctx, "create model failed", "error", err)
+			return err
+		}
+
+		filter := bson.D{{
+			Key:   "ticker",
+			Value: strings.ToUpper(asset.Ticker),
+		}}
+
+		update := bson.D{
+			{
+				Key:   "$set",
+				Value: infoModel,
+			},
+			{
+				Key: "$setOnInsert",
+				Value: bson.D{
+					{
+						Key:   "createdAt",
+						Value: time.Now().UTC().Unix(),
+					},
+					{
+						Key:   "enabled",
+						Value: true,
+					},
+					{
+						Key:   "deleted",
+						Value: false,
+					},
+				},
+			},
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	_, err := col.BulkWrite(ctx, writes, opts)
+	if err != nil {
+		r.log.Error(ctx, "bulk write failed", "error", err)
+		return err
+	}
+
+	return nil
+}
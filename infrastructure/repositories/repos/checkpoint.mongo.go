@@ -26,11 +26,17 @@ type CheckpointMongo struct {
 // NewCheckpointMongo creates new checkpoint mongo repo
 func NewCheckpointMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*CheckpointMongo, error) {
 	if db != nil {
-		return &CheckpointMongo{
+		repo := &CheckpointMongo{
 			db:   db,
 			log:  log,
 			conf: conf,
-		}, nil
+		}
+
+		if err := repo.createIndexes(); err != nil {
+			return repo, err
+		}
+
+		return repo, repo.migrateLegacyCheckpoint()
 	}
 
 	// set context with timeout from the config
@@ -65,12 +71,123 @@ func NewCheckpointMongo(db *mongo.Database, log logger.ContextLog, conf *config.
 		return nil, err
 	}
 
-	return &CheckpointMongo{
+	repo := &CheckpointMongo{
 		db:     client.Database(conf.Dbname),
 		client: client,
 		log:    log,
 		conf:   conf,
-	}, nil
+	}
+
+	if err := repo.createIndexes(); err != nil {
+		return repo, err
+	}
+
+	return repo, repo.migrateLegacyCheckpoint()
+}
+
+// createIndexes creates the checkpoint name index, a job has a single checkpoint so runs racing to
+// create it cannot create two
+func (r *CheckpointMongo) createIndexes() error {
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), r.conf.TimeoutMS)
+	defer cancel()
+
+	keys := bson.D{{
+		Key:   "name",
+		Value: 1,
+	}}
+
+	if err := createIndex(ctx, r.db, r.conf.Colnames, consts.SCRAPE_CHECKPOINT_COLLECTION, keys, true); err != nil {
+		r.log.Error(ctx, "create index failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// migrateLegacyCheckpoint moves the page of the single checkpoint kept before checkpoints were
+// named, a document without a name holding a priceCheckPoint, into the prices checkpoint so the
+// price job carries on where it stopped. The legacy document is deleted instead when the prices
+// checkpoint already exists
+func (r *CheckpointMongo) migrateLegacyCheckpoint() error {
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_CHECKPOINT_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{
+		{
+			Key:   "name",
+			Value: bson.D{{Key: "$exists", Value: false}},
+		},
+		{
+			Key:   "priceCheckPoint",
+			Value: bson.D{{Key: "$exists", Value: true}},
+		},
+	}
+
+	// update
+	update := mongo.Pipeline{
+		{{
+			Key: "$set",
+			Value: bson.D{
+				{
+					Key:   "name",
+					Value: consts.PRICES_CHECKPOINT,
+				},
+				{
+					Key:   "prevIndex",
+					Value: bson.D{{Key: "$ifNull", Value: bson.A{"$priceCheckPoint.prevIndex", -1}}},
+				},
+				{
+					Key:   "size",
+					Value: "$priceCheckPoint.size",
+				},
+				{
+					Key:   "modifiedAt",
+					Value: time.Now().UTC().Unix(),
+				},
+				{
+					Key:   "schema",
+					Value: r.conf.SchemaVersion,
+				},
+			},
+		}},
+		{{
+			Key:   "$unset",
+			Value: "priceCheckPoint",
+		}},
+	}
+
+	res, err := col.UpdateOne(ctx, filter, update)
+	if err == nil {
+		if res.ModifiedCount > 0 {
+			r.log.Info(ctx, "migrated legacy checkpoint", "name", consts.PRICES_CHECKPOINT)
+		}
+		return nil
+	}
+
+	// the prices checkpoint already exists, its page is newer than the legacy one
+	if !mongo.IsDuplicateKeyError(err) {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
+
+	if _, err := col.DeleteMany(ctx, filter); err != nil {
+		r.log.Error(ctx, "delete many failed", "error", err)
+		return err
+	}
+
+	r.log.Info(ctx, "deleted legacy checkpoint", "name", consts.PRICES_CHECKPOINT)
+	return nil
 }
 
 // Close disconnect from database
@@ -91,8 +208,11 @@ func (r *CheckpointMongo) Close() {
 // Implement interface
 ///////////////////////////////////////////////////////////////////////////////
 
-// UpdateCheckpoint updates a checkpoint given page size and number of asset
-func (r *CheckpointMongo) UpdateCheckpoint(ctx context.Context, pageSize int64, numAssets int64) (*entities.Checkpoint, error) {
+// LeaseCheckpoint leases a page of assets of the named checkpoint to the owner for leaseMS. The
+// page of an expired lease, left by a run that did not finish, is reclaimed first, otherwise the
+// checkpoint moves to the next page no live lease holds. Each is a single conditional update, so
+// concurrent runs never lease the same page. It is nil when every page is leased
+func (r *CheckpointMongo) LeaseCheckpoint(ctx context.Context, name string, pageSize int64, numAssets int64, owner string, leaseMS uint64) (*entities.Checkpoint, error) {
 	// create new context for the query
 	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
 	defer cancel()
@@ -105,88 +225,578 @@ func (r *CheckpointMongo) UpdateCheckpoint(ctx context.Context, pageSize int64,
 	}
 	col := r.db.Collection(colname)
 
-	// filter
-	filter := bson.D{}
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Duration(leaseMS) * time.Millisecond).Unix()
+
+	checkpoint, err := r.reclaimExpiredLease(ctx, col, name, owner, now.Unix(), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint != nil {
+		r.log.Info(ctx, "reclaimed expired checkpoint lease", "name", name, "index", checkpoint.PageIndex, "owner", owner)
+		return checkpoint, nil
+	}
+
+	return r.advanceCheckpoint(ctx, col, name, pageSize, numAssets, owner, now.Unix(), expiresAt)
+}
 
-	// find options
-	findOptions := options.FindOne()
+// reclaimExpiredLease hands an expired lease over to the owner, nil when no lease has expired
+func (r *CheckpointMongo) reclaimExpiredLease(ctx context.Context, col *mongo.Collection, name string, owner string, now int64, expiresAt int64) (*entities.Checkpoint, error) {
+	// filter
+	filter := bson.D{
+		{
+			Key:   "name",
+			Value: name,
+		},
+		{
+			Key: "leases",
+			Value: bson.D{{
+				Key: "$elemMatch",
+				Value: bson.D{{
+					Key:   "expiresAt",
+					Value: bson.D{{Key: "$lt", Value: now}},
+				}},
+			}},
+		},
+	}
 
-	cur := col.FindOne(ctx, filter, findOptions)
+	// update the matched lease
+	update := bson.D{{
+		Key: "$set",
+		Value: bson.D{
+			{
+				Key:   "leases.$.owner",
+				Value: owner,
+			},
+			{
+				Key:   "leases.$.expiresAt",
+				Value: expiresAt,
+			},
+			{
+				Key:   "modifiedAt",
+				Value: now,
+			},
+		},
+	}}
 
-	// only run defer function when find success
-	err := cur.Err()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
+	var checkpoint models.CheckPointModel
+	err := col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&checkpoint)
 	if err == mongo.ErrNoDocuments {
-		// decode cursor to activity model
-		cp, err := models.NewCheckPointModel(ctx, r.log, pageSize, r.conf.SchemaVersion)
+		return nil, nil
+	}
+
+	if err != nil {
+		r.log.Error(ctx, "find one and update failed", "error", err)
+		return nil, err
+	}
+
+	return checkpoint.ToLeaseEntity(owner), nil
+}
+
+// checkpointAttempts is how many times a run tries to advance a checkpoint another run keeps
+// moving first
+const checkpointAttempts = 3
+
+// advanceCheckpoint moves the checkpoint to the next page no live lease holds, back to the first
+// one after the last page, and leases it to the owner. It is nil when every page is leased
+func (r *CheckpointMongo) advanceCheckpoint(ctx context.Context, col *mongo.Collection, name string, pageSize int64, numAssets int64, owner string, now int64, expiresAt int64) (*entities.Checkpoint, error) {
+	for attempt := 1; attempt <= checkpointAttempts; attempt++ {
+		checkpoint, moved, err := r.tryAdvanceCheckpoint(ctx, col, name, pageSize, numAssets, owner, now, expiresAt)
 		if err != nil {
-			r.log.Error(ctx, "create model failed", "error", err)
 			return nil, err
 		}
 
-		return r.updateCheckPoint(ctx, col, cp)
+		if !moved {
+			return checkpoint, nil
+		}
+
+		r.log.Info(ctx, "checkpoint moved by another run", "name", name, "attempt", attempt)
 	}
 
-	// find was not succeed
-	if err != nil {
-		r.log.Error(ctx, "find query failed", "error", err)
-		return nil, err
+	r.log.Error(ctx, "advance checkpoint failed", "name", name, "attempts", checkpointAttempts)
+	return nil, fmt.Errorf("checkpoint %s moved by other runs %d times", name, checkpointAttempts)
+}
+
+// tryAdvanceCheckpoint leases the page after the one of the checkpoint as it is read, moved is
+// true when another run changed the checkpoint in between and nothing was leased
+func (r *CheckpointMongo) tryAdvanceCheckpoint(ctx context.Context, col *mongo.Collection, name string, pageSize int64, numAssets int64, owner string, now int64, expiresAt int64) (*entities.Checkpoint, bool, error) {
+	// filter
+	filter := bson.D{{
+		Key:   "name",
+		Value: name,
+	}}
+
+	var current models.CheckPointModel
+	err := col.FindOne(ctx, filter).Decode(&current)
+	exists := err == nil
+	if err != nil && err != mongo.ErrNoDocuments {
+		r.log.Error(ctx, "find one failed", "error", err)
+		return nil, false, err
+	}
+
+	// the first run starts at the first page
+	prevIndex := int64(-1)
+	if exists {
+		prevIndex = current.PrevIndex
+	}
+
+	pageIndex, ok := nextFreePage(prevIndex, pageSize, numAssets, current.Leases, now)
+	if !ok {
+		r.log.Info(ctx, "every checkpoint page is leased", "name", name)
+		return nil, false, nil
+	}
+
+	// the update only applies while the checkpoint is still at the page read and no run has
+	// leased the next one since
+	if exists {
+		filter = append(filter, bson.E{
+			Key:   "prevIndex",
+			Value: current.PrevIndex,
+		})
+	} else {
+		filter = append(filter, bson.E{
+			Key:   "prevIndex",
+			Value: bson.D{{Key: "$exists", Value: false}},
+		})
+	}
+
+	filter = append(filter, bson.E{
+		Key: "leases",
+		Value: bson.D{{
+			Key: "$not",
+			Value: bson.D{{
+				Key: "$elemMatch",
+				Value: bson.D{
+					{
+						Key:   "index",
+						Value: pageIndex,
+					},
+					{
+						Key:   "expiresAt",
+						Value: bson.D{{Key: "$gte", Value: now}},
+					},
+				},
+			}},
+		}},
+	})
+
+	// an expired lease left on the page is replaced
+	leases := bson.D{{
+		Key: "$concatArrays",
+		Value: bson.A{
+			bson.D{{
+				Key: "$filter",
+				Value: bson.D{
+					{
+						Key:   "input",
+						Value: bson.D{{Key: "$ifNull", Value: bson.A{"$leases", bson.A{}}}},
+					},
+					{
+						Key:   "as",
+						Value: "lease",
+					},
+					{
+						Key:   "cond",
+						Value: bson.D{{Key: "$ne", Value: bson.A{"$$lease.index", pageIndex}}},
+					},
+				},
+			}},
+			bson.A{
+				bson.D{
+					{
+						Key:   "index",
+						Value: pageIndex,
+					},
+					{
+						Key:   "size",
+						Value: pageSize,
+					},
+					{
+						Key:   "owner",
+						Value: owner,
+					},
+					{
+						Key:   "expiresAt",
+						Value: expiresAt,
+					},
+				},
+			},
+		},
+	}}
+
+	update := mongo.Pipeline{
+		{{
+			Key: "$set",
+			Value: bson.D{
+				{
+					Key:   "prevIndex",
+					Value: pageIndex,
+				},
+				{
+					Key:   "size",
+					Value: pageSize,
+				},
+				{
+					Key:   "name",
+					Value: name,
+				},
+				{
+					Key:   "createdAt",
+					Value: bson.D{{Key: "$ifNull", Value: bson.A{"$createdAt", now}}},
+				},
+				{
+					Key:   "modifiedAt",
+					Value: now,
+				},
+				{
+					Key:   "enabled",
+					Value: true,
+				},
+				{
+					Key:   "deleted",
+					Value: false,
+				},
+				{
+					Key:   "schema",
+					Value: r.conf.SchemaVersion,
+				},
+				{
+					Key:   "leases",
+					Value: leases,
+				},
+			},
+		}},
 	}
 
+	// only the first run creates the checkpoint, a run racing it fails on the unique name index
+	opts := options.FindOneAndUpdate().SetUpsert(!exists).SetReturnDocument(options.After)
+
 	var checkpoint models.CheckPointModel
-	if err = cur.Decode(&checkpoint); err != nil {
-		r.log.Error(ctx, "decode failed", "error", err)
-		return nil, err
+	err = col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&checkpoint)
+	if err == mongo.ErrNoDocuments || mongo.IsDuplicateKeyError(err) {
+		return nil, true, nil
+	}
+
+	if err != nil {
+		r.log.Error(ctx, "find one and update failed", "error", err)
+		return nil, false, err
+	}
+
+	lease := checkpoint.ToLeaseEntity(owner)
+	if lease == nil {
+		r.log.Error(ctx, "checkpoint lease not found", "name", name, "owner", owner)
+		return nil, false, fmt.Errorf("checkpoint %s lease of %s not found", name, owner)
+	}
+
+	return lease, false, nil
+}
+
+// nextFreePage returns the first page after prevIndex no live lease holds, wrapping back to the
+// first page after the last one. It is false when every page is leased
+func nextFreePage(prevIndex int64, pageSize int64, numAssets int64, leases []*models.CheckPointLeaseModel, now int64) (int64, bool) {
+	numPages := int64(1)
+	if pageSize > 0 && numAssets > pageSize {
+		numPages = (numAssets + pageSize - 1) / pageSize
 	}
 
-	if checkpoint.PriceCheckPoint == nil {
-		checkpoint.PriceCheckPoint = &models.PriceCheckPointModel{
-			PageSize:  pageSize,
-			PrevIndex: 0,
+	leased := map[int64]bool{}
+	for _, lease := range leases {
+		if lease.ExpiresAt >= now {
+			leased[lease.PageIndex] = true
 		}
-		return r.updateCheckPoint(ctx, col, &checkpoint)
 	}
 
-	currNumAssets := checkpoint.PriceCheckPoint.PrevIndex*checkpoint.PriceCheckPoint.PageSize + checkpoint.PriceCheckPoint.PageSize
-	if currNumAssets >= numAssets {
-		checkpoint.PriceCheckPoint.PrevIndex = 0
-	} else {
-		checkpoint.PriceCheckPoint.PrevIndex = checkpoint.PriceCheckPoint.PrevIndex + 1
+	pageIndex := prevIndex
+	for i := int64(0); i < numPages; i++ {
+		pageIndex++
+		if pageIndex < 0 || pageIndex*pageSize >= numAssets {
+			pageIndex = 0
+		}
+
+		if !leased[pageIndex] {
+			return pageIndex, true
+		}
+	}
+
+	return 0, false
+}
+
+// ReleaseCheckpoint removes the lease of the owner on the named checkpoint once its page is
+// processed, and records when the page was completed
+func (r *CheckpointMongo) ReleaseCheckpoint(ctx context.Context, name string, owner string) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_CHECKPOINT_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	now := time.Now().UTC().Unix()
+
+	// the leases of the owner
+	ownerLeases := bson.D{{
+		Key: "$filter",
+		Value: bson.D{
+			{
+				Key:   "input",
+				Value: bson.D{{Key: "$ifNull", Value: bson.A{"$leases", bson.A{}}}},
+			},
+			{
+				Key:   "as",
+				Value: "lease",
+			},
+			{
+				Key:   "cond",
+				Value: bson.D{{Key: "$eq", Value: bson.A{"$$lease.owner", owner}}},
+			},
+		},
+	}}
+
+	ownerIndexes := bson.D{{
+		Key: "$map",
+		Value: bson.D{
+			{
+				Key:   "input",
+				Value: ownerLeases,
+			},
+			{
+				Key:   "as",
+				Value: "lease",
+			},
+			{
+				Key:   "in",
+				Value: "$$lease.index",
+			},
+		},
+	}}
+
+	// the completion of the released pages replaces their previous one
+	completedPages := bson.D{{
+		Key: "$concatArrays",
+		Value: bson.A{
+			bson.D{{
+				Key: "$filter",
+				Value: bson.D{
+					{
+						Key:   "input",
+						Value: bson.D{{Key: "$ifNull", Value: bson.A{"$completedPages", bson.A{}}}},
+					},
+					{
+						Key:   "as",
+						Value: "page",
+					},
+					{
+						Key:   "cond",
+						Value: bson.D{{Key: "$not", Value: bson.A{bson.D{{Key: "$in", Value: bson.A{"$$page.index", ownerIndexes}}}}}},
+					},
+				},
+			}},
+			bson.D{{
+				Key: "$map",
+				Value: bson.D{
+					{
+						Key:   "input",
+						Value: ownerLeases,
+					},
+					{
+						Key:   "as",
+						Value: "lease",
+					},
+					{
+						Key: "in",
+						Value: bson.D{
+							{
+								Key:   "index",
+								Value: "$$lease.index",
+							},
+							{
+								Key:   "completedAt",
+								Value: now,
+							},
+						},
+					},
+				},
+			}},
+		},
+	}}
+
+	leases := bson.D{{
+		Key: "$filter",
+		Value: bson.D{
+			{
+				Key:   "input",
+				Value: bson.D{{Key: "$ifNull", Value: bson.A{"$leases", bson.A{}}}},
+			},
+			{
+				Key:   "as",
+				Value: "lease",
+			},
+			{
+				Key:   "cond",
+				Value: bson.D{{Key: "$ne", Value: bson.A{"$$lease.owner", owner}}},
+			},
+		},
+	}}
+
+	update := mongo.Pipeline{
+		{{
+			Key: "$set",
+			Value: bson.D{
+				{
+					Key:   "completedPages",
+					Value: completedPages,
+				},
+				{
+					Key:   "modifiedAt",
+					Value: now,
+				},
+			},
+		}},
+		{{
+			Key: "$set",
+			Value: bson.D{{
+				Key:   "leases",
+				Value: leases,
+			}},
+		}},
 	}
 
-	checkpoint.PriceCheckPoint.PageSize = pageSize
+	// filter
+	filter := bson.D{
+		{
+			Key:   "name",
+			Value: name,
+		},
+		{
+			Key:   "leases.owner",
+			Value: owner,
+		},
+	}
+
+	if _, err := col.UpdateOne(ctx, filter, update); err != nil {
+		r.log.Error(ctx, "update one failed", "error", err)
+		return err
+	}
 
-	return r.updateCheckPoint(ctx, col, &checkpoint)
+	return nil
 }
 
-// updateCheckPoint update checkpoint
-func (r *CheckpointMongo) updateCheckPoint(ctx context.Context, col *mongo.Collection, checkpoint *models.CheckPointModel) (*entities.Checkpoint, error) {
+// FindCheckpoint finds the named checkpoint, nil when it does not exist yet
+func (r *CheckpointMongo) FindCheckpoint(ctx context.Context, name string) (*entities.Checkpoint, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_CHECKPOINT_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
 	// filter
-	filter := bson.D{}
-	if checkpoint.ID != nil {
-		filter = bson.D{{Key: "_id", Value: checkpoint.ID}}
-	} else {
-		checkpoint.CreatedAt = time.Now().UTC().Unix()
+	filter := bson.D{{
+		Key:   "name",
+		Value: name,
+	}}
+
+	var checkpoint models.CheckPointModel
+	err := col.FindOne(ctx, filter).Decode(&checkpoint)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.log.Error(ctx, "find one failed", "error", err)
+		return nil, err
+	}
+
+	return checkpoint.ToEntity(), nil
+}
+
+// ResetCheckpoint moves the named checkpoint back to the first page
+func (r *CheckpointMongo) ResetCheckpoint(ctx context.Context, name string) error {
+	// the first page follows the page before it
+	return r.updateCheckpointIndex(ctx, name, -1)
+}
+
+// SetCheckpoint moves the named checkpoint so the next run leases pageIndex
+func (r *CheckpointMongo) SetCheckpoint(ctx context.Context, name string, pageIndex int64) error {
+	return r.updateCheckpointIndex(ctx, name, pageIndex-1)
+}
+
+// updateCheckpointIndex sets the previous page index of the named checkpoint. Its leases are kept,
+// a run still on its page releases it and an expired one is reclaimed as usual
+func (r *CheckpointMongo) updateCheckpointIndex(ctx context.Context, name string, prevIndex int64) error {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_CHECKPOINT_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
 	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{{
+		Key:   "name",
+		Value: name,
+	}}
 
 	// update
 	update := bson.D{
 		{
-			Key:   "$set",
-			Value: checkpoint,
+			Key: "$set",
+			Value: bson.D{
+				{
+					Key:   "prevIndex",
+					Value: prevIndex,
+				},
+				{
+					Key:   "modifiedAt",
+					Value: time.Now().UTC().Unix(),
+				},
+				{
+					Key:   "enabled",
+					Value: true,
+				},
+				{
+					Key:   "deleted",
+					Value: false,
+				},
+				{
+					Key:   "schema",
+					Value: r.conf.SchemaVersion,
+				},
+			},
+		},
+		{
+			Key: "$setOnInsert",
+			Value: bson.D{{
+				Key:   "createdAt",
+				Value: time.Now().UTC().Unix(),
+			}},
 		},
 	}
 
 	opts := options.Update().SetUpsert(true)
 
-	_, err := col.UpdateOne(ctx, filter, update, opts)
-	if err != nil {
+	if _, err := col.UpdateOne(ctx, filter, update, opts); err != nil {
 		r.log.Error(ctx, "update one failed", "error", err)
-		return nil, err
+		return err
 	}
 
-	return &entities.Checkpoint{
-		PageSize:  checkpoint.PriceCheckPoint.PageSize,
-		PageIndex: checkpoint.PriceCheckPoint.PrevIndex,
-	}, nil
+	return nil
 }
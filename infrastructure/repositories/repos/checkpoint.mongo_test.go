@@ -0,0 +1,46 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
+)
+
+func TestNextFreePage(t *testing.T) {
+	const now = 1000
+
+	live := func(index int64) *models.CheckPointLeaseModel {
+		return &models.CheckPointLeaseModel{PageIndex: index, ExpiresAt: now + 60}
+	}
+
+	expired := func(index int64) *models.CheckPointLeaseModel {
+		return &models.CheckPointLeaseModel{PageIndex: index, ExpiresAt: now - 1}
+	}
+
+	tests := []struct {
+		name      string
+		prevIndex int64
+		numAssets int64
+		leases    []*models.CheckPointLeaseModel
+		want      int64
+		wantOK    bool
+	}{
+		{name: "first run", prevIndex: -1, numAssets: 25, want: 0, wantOK: true},
+		{name: "next page", prevIndex: 0, numAssets: 25, want: 1, wantOK: true},
+		{name: "wraps after the last page", prevIndex: 2, numAssets: 25, want: 0, wantOK: true},
+		{name: "wraps when the assets shrank", prevIndex: 7, numAssets: 25, want: 0, wantOK: true},
+		{name: "skips a live lease", prevIndex: 0, numAssets: 25, leases: []*models.CheckPointLeaseModel{live(1)}, want: 2, wantOK: true},
+		{name: "skips live leases across the wrap", prevIndex: 1, numAssets: 25, leases: []*models.CheckPointLeaseModel{live(2), live(0)}, want: 1, wantOK: true},
+		{name: "takes an expired lease", prevIndex: 0, numAssets: 25, leases: []*models.CheckPointLeaseModel{expired(1)}, want: 1, wantOK: true},
+		{name: "every page leased", prevIndex: 0, numAssets: 25, leases: []*models.CheckPointLeaseModel{live(0), live(1), live(2)}, wantOK: false},
+		{name: "no assets", prevIndex: 3, numAssets: 0, want: 0, wantOK: true},
+		{name: "single page leased", prevIndex: 0, numAssets: 0, leases: []*models.CheckPointLeaseModel{live(0)}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := nextFreePage(tt.prevIndex, 10, tt.numAssets, tt.leases, now)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("%s: nextFreePage() = %d, %v, want %d, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
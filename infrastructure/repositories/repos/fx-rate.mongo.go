@@ -0,0 +1,214 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FxRateMongo struct
+type FxRateMongo struct {
+	db     *mongo.Database
+	client *mongo.Client
+	log    logger.ContextLog
+	conf   *config.MongoConfig
+}
+
+// NewFxRateMongo creates new fx rate mongo repo
+func NewFxRateMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*FxRateMongo, error) {
+	if db != nil {
+		return &FxRateMongo{
+			db:   db,
+			log:  log,
+			conf: conf,
+		}, nil
+	}
+
+	// set context with timeout from the config
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), conf.TimeoutMS)
+	defer cancel()
+
+	// set mongo client options
+	clientOptions := options.Client()
+
+	// set min pool size
+	if conf.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(conf.MinPoolSize)
+	}
+
+	// set max pool size
+	if conf.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(conf.MaxPoolSize)
+	}
+
+	// set max idle time ms
+	if conf.MaxIdleTimeMS > 0 {
+		clientOptions.SetMaxConnIdleTime(time.Duration(conf.MaxIdleTimeMS) * time.Millisecond)
+	}
+
+	// construct a connection string from mongo config object
+	cxnString := fmt.Sprintf("mongodb+srv://%s:%s@%s", conf.Username, conf.Password, conf.Host)
+
+	// create mongo client by making new connection
+	client, err := mongo.Connect(ctx, clientOptions.ApplyURI(cxnString))
+	if err != nil {
+		return nil, err
+	}
+
+	return &FxRateMongo{
+		db:     client.Database(conf.Dbname),
+		client: client,
+		log:    log,
+		conf:   conf,
+	}, nil
+}
+
+// Close disconnect from database
+func (r *FxRateMongo) Close() {
+	ctx := context.Background()
+	r.log.Info(ctx, "close mongo client")
+
+	if r.client == nil {
+		return
+	}
+
+	if err := r.client.Disconnect(ctx); err != nil {
+		r.log.Error(ctx, "disconnect mongo failed", "error", err)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Implement interface
+///////////////////////////////////////////////////////////////////////////////
+
+// UpsertFxRates bulk upserts the latest rate of each currency pair
+func (r *FxRateMongo) UpsertFxRates(ctx context.Context, fxRates []*entities.FxRate) error {
+	if len(fxRates) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.FX_RATES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	var writes []mongo.WriteModel
+	for _, fxRate := range fxRates {
+		fxRateModel, err := models.NewFxRateModel(ctx, r.log, fxRate, r.conf.SchemaVersion)
+		if err != nil {
+			r.log.Error(ctx, "create model failed", "error", err)
+			return err
+		}
+
+		filter := bson.D{{
+			Key:   "pair",
+			Value: fxRateModel.Pair,
+		}}
+
+		update := bson.D{
+			{
+				Key:   "$set",
+				Value: fxRateModel,
+			},
+			{
+				Key: "$setOnInsert",
+				Value: bson.D{{
+					Key:   "createdAt",
+					Value: time.Now().UTC().Unix(),
+				}},
+			},
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	_, err := col.BulkWrite(ctx, writes, opts)
+	if err != nil {
+		r.log.Error(ctx, "bulk write failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindFxRates find the rates of the currency pairs quoted in the given currency
+func (r *FxRateMongo) FindFxRates(ctx context.Context, to string) ([]*entities.FxRate, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.FX_RATES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{{
+		Key:   "to",
+		Value: strings.ToUpper(to),
+	}}
+
+	// find options
+	findOptions := options.Find()
+
+	cur, err := col.Find(ctx, filter, findOptions)
+
+	// only run defer function when find success
+	if cur != nil {
+		defer func() {
+			if deferErr := cur.Close(ctx); deferErr != nil {
+				err = deferErr
+			}
+		}()
+	}
+
+	// find was not succeed
+	if err != nil {
+		r.log.Error(ctx, "find query failed", "error", err)
+		return nil, err
+	}
+
+	var fxRates []*entities.FxRate
+
+	// iterate over the cursor to decode document one at a time
+	for cur.Next(ctx) {
+		// decode cursor to fx rate model
+		var fxRate models.FxRateModel
+		if err = cur.Decode(&fxRate); err != nil {
+			r.log.Error(ctx, "decode failed", "error", err)
+			return nil, err
+		}
+
+		fxRates = append(fxRates, fxRate.ToEntity())
+	}
+
+	if err := cur.Err(); err != nil {
+		r.log.Error(ctx, "iterate over cursor failed", "error", err)
+		return nil, err
+	}
+
+	return fxRates, nil
+}
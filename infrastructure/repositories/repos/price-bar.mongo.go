@@ -0,0 +1,234 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PriceBarMongo struct
+type PriceBarMongo struct {
+	db     *mongo.Database
+	client *mongo.Client
+	log    logger.ContextLog
+	conf   *config.MongoConfig
+}
+
+// NewPriceBarMongo creates new price bar mongo repo
+func NewPriceBarMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*PriceBarMongo, error) {
+	if db != nil {
+		return &PriceBarMongo{
+			db:   db,
+			log:  log,
+			conf: conf,
+		}, nil
+	}
+
+	// set context with timeout from the config
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), conf.TimeoutMS)
+	defer cancel()
+
+	// set mongo client options
+	clientOptions := options.Client()
+
+	// set min pool size
+	if conf.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(conf.MinPoolSize)
+	}
+
+	// set max pool size
+	if conf.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(conf.MaxPoolSize)
+	}
+
+	// set max idle time ms
+	if conf.MaxIdleTimeMS > 0 {
+		clientOptions.SetMaxConnIdleTime(time.Duration(conf.MaxIdleTimeMS) * time.Millisecond)
+	}
+
+	// construct a connection string from mongo config object
+	cxnString := fmt.Sprintf("mongodb+srv://%s:%s@%s", conf.Username, conf.Password, conf.Host)
+
+	// create mongo client by making new connection
+	client, err := mongo.Connect(ctx, clientOptions.ApplyURI(cxnString))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PriceBarMongo{
+		db:     client.Database(conf.Dbname),
+		client: client,
+		log:    log,
+		conf:   conf,
+	}, nil
+}
+
+// Close disconnect from database
+func (r *PriceBarMongo) Close() {
+	ctx := context.Background()
+	r.log.Info(ctx, "close mongo client")
+
+	if r.client == nil {
+		return
+	}
+
+	if err := r.client.Disconnect(ctx); err != nil {
+		r.log.Error(ctx, "disconnect mongo failed", "error", err)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Implement interface
+///////////////////////////////////////////////////////////////////////////////
+
+// InsertPriceBars bulk upserts daily price bars keyed by ticker and date
+func (r *PriceBarMongo) InsertPriceBars(ctx context.Context, bars []*entities.PriceBar) error {
+	if len(bars) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICE_BARS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	var writes []mongo.WriteModel
+	for _, bar := range bars {
+		barModel, err := models.NewPriceBarModel(ctx, r.log, bar, r.conf.SchemaVersion)
+		if err != nil {
+			r.log.Error(ctx, "create model failed", "error", err)
+			return err
+		}
+
+		filter := bson.D{
+			{
+				Key:   "ticker",
+				Value: barModel.Ticker,
+			},
+			{
+				Key:   "date",
+				Value: barModel.Date,
+			},
+		}
+
+		update := bson.D{
+			{
+				Key:   "$set",
+				Value: barModel,
+			},
+			{
+				Key: "$setOnInsert",
+				Value: bson.D{{
+					Key:   "createdAt",
+					Value: time.Now().UTC().Unix(),
+				}},
+			},
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	_, err := col.BulkWrite(ctx, writes, opts)
+	if err != nil {
+		r.log.Error(ctx, "bulk write failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// AdjustPriceBars multiplies the adjustment factor of the daily bars of a ticker dated before the
+// given date and fetched before fetchedBefore (unix seconds) by factor, returns the number of
+// adjusted bars
+func (r *PriceBarMongo) AdjustPriceBars(ctx context.Context, ticker string, before int64, fetchedBefore int64, factor float64) (int64, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.ASSET_PRICE_BARS_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return 0, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{
+		{
+			Key:   "ticker",
+			Value: ticker,
+		},
+		{
+			Key:   "date",
+			Value: bson.D{{Key: "$lt", Value: before}},
+		},
+		// bars fetched once the split was known are adjusted by the chart already, bars
+		// stored before the fetch time was recorded are not
+		{
+			Key: "$or",
+			Value: bson.A{
+				bson.D{{
+					Key:   "fetchedAt",
+					Value: bson.D{{Key: "$lt", Value: fetchedBefore}},
+				}},
+				bson.D{{
+					Key:   "fetchedAt",
+					Value: bson.D{{Key: "$exists", Value: false}},
+				}},
+			},
+		},
+	}
+
+	// bars stored before adjustments existed have no factor yet
+	adjFactor := bson.D{{
+		Key: "$multiply",
+		Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$adjFactor", 1}}},
+			factor,
+		},
+	}}
+
+	update := mongo.Pipeline{
+		{{
+			Key: "$set",
+			Value: bson.D{
+				{
+					Key:   "adjFactor",
+					Value: adjFactor,
+				},
+				{
+					Key:   "modifiedAt",
+					Value: time.Now().UTC().Unix(),
+				},
+			},
+		}},
+	}
+
+	res, err := col.UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.log.Error(ctx, "update many failed", "error", err)
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
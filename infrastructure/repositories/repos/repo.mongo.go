@@ -2,8 +2,13 @@ package repos
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // createContext create a new context with timeout
@@ -21,3 +26,20 @@ func stringsToUpperCase(strs []string) (upperStrings []string, err error) {
 
 	return upperStrings, nil
 }
+
+// createIndex creates an index of the keys on the collection, creating an index that already
+// exists does nothing
+func createIndex(ctx context.Context, db *mongo.Database, colnames map[string]string, collection string, keys bson.D, unique bool) error {
+	colname, ok := colnames[collection]
+	if !ok {
+		return fmt.Errorf("cannot find collection name")
+	}
+
+	index := mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetUnique(unique),
+	}
+
+	_, err := db.Collection(colname).Indexes().CreateOne(ctx, index)
+	return err
+}
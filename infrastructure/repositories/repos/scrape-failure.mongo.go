@@ -0,0 +1,192 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScrapeFailureMongo struct
+type ScrapeFailureMongo struct {
+	db     *mongo.Database
+	client *mongo.Client
+	log    logger.ContextLog
+	conf   *config.MongoConfig
+}
+
+// NewScrapeFailureMongo creates new scrape failure mongo repo
+func NewScrapeFailureMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*ScrapeFailureMongo, error) {
+	if db != nil {
+		return &ScrapeFailureMongo{
+			db:   db,
+			log:  log,
+			conf: conf,
+		}, nil
+	}
+
+	// set context with timeout from the config
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), conf.TimeoutMS)
+	defer cancel()
+
+	// set mongo client options
+	clientOptions := options.Client()
+
+	// set min pool size
+	if conf.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(conf.MinPoolSize)
+	}
+
+	// set max pool size
+	if conf.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(conf.MaxPoolSize)
+	}
+
+	// set max idle time ms
+	if conf.MaxIdleTimeMS > 0 {
+		clientOptions.SetMaxConnIdleTime(time.Duration(conf.MaxIdleTimeMS) * time.Millisecond)
+	}
+
+	// construct a connection string from mongo config object
+	cxnString := fmt.Sprintf("mongodb+srv://%s:%s@%s", conf.Username, conf.Password, conf.Host)
+
+	// create mongo client by making new connection
+	client, err := mongo.Connect(ctx, clientOptions.ApplyURI(cxnString))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScrapeFailureMongo{
+		db:     client.Database(conf.Dbname),
+		client: client,
+		log:    log,
+		conf:   conf,
+	}, nil
+}
+
+// Close disconnect from database
+func (r *ScrapeFailureMongo) Close() {
+	ctx := context.Background()
+	r.log.Info(ctx, "close mongo client")
+
+	if r.client == nil {
+		return
+	}
+
+	if err := r.client.Disconnect(ctx); err != nil {
+		r.log.Error(ctx, "disconnect mongo failed", "error", err)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Implement interface
+///////////////////////////////////////////////////////////////////////////////
+
+// UpsertScrapeFailure records the last failure of a ticker, counts one more consecutive failure
+// and returns the updated record
+func (r *ScrapeFailureMongo) UpsertScrapeFailure(ctx context.Context, failure *entities.ScrapeFailure) (*entities.ScrapeFailure, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	failureModel, err := models.NewScrapeFailureModel(ctx, r.log, failure, r.conf.SchemaVersion)
+	if err != nil {
+		r.log.Error(ctx, "create model failed", "error", err)
+		return nil, err
+	}
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_FAILURES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	filter := bson.D{{
+		Key:   "ticker",
+		Value: failureModel.Ticker,
+	}}
+
+	update := bson.D{
+		{
+			Key:   "$set",
+			Value: failureModel,
+		},
+		{
+			Key: "$inc",
+			Value: bson.D{{
+				Key:   "consecutiveFailures",
+				Value: 1,
+			}},
+		},
+		{
+			Key: "$setOnInsert",
+			Value: bson.D{
+				{
+					Key:   "createdAt",
+					Value: time.Now().UTC().Unix(),
+				},
+				{
+					Key:   "firstFailedAt",
+					Value: failureModel.LastFailedAt,
+				},
+			},
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var updated models.ScrapeFailureModel
+	if err := col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		r.log.Error(ctx, "find one and update failed", "error", err)
+		return nil, err
+	}
+
+	return updated.ToEntity(), nil
+}
+
+// DeleteScrapeFailures delete the failures of the given tickers
+func (r *ScrapeFailureMongo) DeleteScrapeFailures(ctx context.Context, tickers []string) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_FAILURES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{{
+		Key: "ticker",
+		Value: bson.D{{
+			Key:   "$in",
+			Value: tickers,
+		}},
+	}}
+
+	_, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		r.log.Error(ctx, "delete many failed", "error", err)
+		return err
+	}
+
+	return nil
+}
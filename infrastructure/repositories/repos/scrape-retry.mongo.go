@@ -0,0 +1,253 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/infrastructure/repositories/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScrapeRetryMongo struct
+type ScrapeRetryMongo struct {
+	db     *mongo.Database
+	client *mongo.Client
+	log    logger.ContextLog
+	conf   *config.MongoConfig
+}
+
+// NewScrapeRetryMongo creates new scrape retry mongo repo
+func NewScrapeRetryMongo(db *mongo.Database, log logger.ContextLog, conf *config.MongoConfig) (*ScrapeRetryMongo, error) {
+	if db != nil {
+		return &ScrapeRetryMongo{
+			db:   db,
+			log:  log,
+			conf: conf,
+		}, nil
+	}
+
+	// set context with timeout from the config
+	// create new context for the query
+	ctx, cancel := createContext(context.Background(), conf.TimeoutMS)
+	defer cancel()
+
+	// set mongo client options
+	clientOptions := options.Client()
+
+	// set min pool size
+	if conf.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(conf.MinPoolSize)
+	}
+
+	// set max pool size
+	if conf.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(conf.MaxPoolSize)
+	}
+
+	// set max idle time ms
+	if conf.MaxIdleTimeMS > 0 {
+		clientOptions.SetMaxConnIdleTime(time.Duration(conf.MaxIdleTimeMS) * time.Millisecond)
+	}
+
+	// construct a connection string from mongo config object
+	cxnString := fmt.Sprintf("mongodb+srv://%s:%s@%s", conf.Username, conf.Password, conf.Host)
+
+	// create mongo client by making new connection
+	client, err := mongo.Connect(ctx, clientOptions.ApplyURI(cxnString))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScrapeRetryMongo{
+		db:     client.Database(conf.Dbname),
+		client: client,
+		log:    log,
+		conf:   conf,
+	}, nil
+}
+
+// Close disconnect from database
+func (r *ScrapeRetryMongo) Close() {
+	ctx := context.Background()
+	r.log.Info(ctx, "close mongo client")
+
+	if r.client == nil {
+		return
+	}
+
+	if err := r.client.Disconnect(ctx); err != nil {
+		r.log.Error(ctx, "disconnect mongo failed", "error", err)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Implement interface
+///////////////////////////////////////////////////////////////////////////////
+
+// InsertScrapeRetries upserts the retries keyed by ticker and counts one more attempt for each
+func (r *ScrapeRetryMongo) InsertScrapeRetries(ctx context.Context, retries []*entities.ScrapeRetry) error {
+	if len(retries) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_RETRIES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	var writes []mongo.WriteModel
+	for _, retry := range retries {
+		retryModel, err := models.NewScrapeRetryModel(ctx, r.log, retry, r.conf.SchemaVersion)
+		if err != nil {
+			r.log.Error(ctx, "create model failed", "error", err)
+			return err
+		}
+
+		filter := bson.D{{
+			Key:   "ticker",
+			Value: retryModel.Ticker,
+		}}
+
+		update := bson.D{
+			{
+				Key:   "$set",
+				Value: retryModel,
+			},
+			{
+				Key: "$inc",
+				Value: bson.D{{
+					Key:   "attempts",
+					Value: 1,
+				}},
+			},
+			{
+				Key: "$setOnInsert",
+				Value: bson.D{{
+					Key:   "createdAt",
+					Value: time.Now().UTC().Unix(),
+				}},
+			},
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	_, err := col.BulkWrite(ctx, writes, opts)
+	if err != nil {
+		r.log.Error(ctx, "bulk write failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindScrapeRetries find the oldest retries, at most limit of them
+func (r *ScrapeRetryMongo) FindScrapeRetries(ctx context.Context, limit int64) ([]*entities.ScrapeRetry, error) {
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_RETRIES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return nil, fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{}
+
+	// find options
+	findOptions := options.Find().SetSort(bson.D{{Key: "failedAt", Value: 1}}).SetLimit(limit)
+
+	cur, err := col.Find(ctx, filter, findOptions)
+
+	// only run defer function when find success
+	if cur != nil {
+		defer func() {
+			if deferErr := cur.Close(ctx); deferErr != nil {
+				err = deferErr
+			}
+		}()
+	}
+
+	// find was not succeed
+	if err != nil {
+		r.log.Error(ctx, "find query failed", "error", err)
+		return nil, err
+	}
+
+	var retries []*entities.ScrapeRetry
+
+	// iterate over the cursor to decode document one at a time
+	for cur.Next(ctx) {
+		// decode cursor to scrape retry model
+		var retry models.ScrapeRetryModel
+		if err = cur.Decode(&retry); err != nil {
+			r.log.Error(ctx, "decode failed", "error", err)
+			return nil, err
+		}
+
+		retries = append(retries, retry.ToEntity())
+	}
+
+	if err := cur.Err(); err != nil {
+		r.log.Error(ctx, "iterate over cursor failed", "error", err)
+		return nil, err
+	}
+
+	return retries, nil
+}
+
+// DeleteScrapeRetries delete the retries of the given tickers
+func (r *ScrapeRetryMongo) DeleteScrapeRetries(ctx context.Context, tickers []string) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+
+	// create new context for the query
+	ctx, cancel := createContext(ctx, r.conf.TimeoutMS)
+	defer cancel()
+
+	// what collection we are going to use
+	colname, ok := r.conf.Colnames[consts.SCRAPE_RETRIES_COLLECTION]
+	if !ok {
+		r.log.Error(ctx, "cannot find collection name")
+		return fmt.Errorf("cannot find collection name")
+	}
+	col := r.db.Collection(colname)
+
+	// filter
+	filter := bson.D{{
+		Key: "ticker",
+		Value: bson.D{{
+			Key:   "$in",
+			Value: tickers,
+		}},
+	}}
+
+	_, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		r.log.Error(ctx, "delete many failed", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package scraper
+
+import (
+	"context"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/enrichment"
+)
+
+// AssetEnricher struct
+type AssetEnricher struct {
+	enrichmentService *enrichment.Service
+	assetService      *assets.Service
+	log               logger.ContextLog
+	errorTickers      []string
+	mismatchTickers   []string
+}
+
+// NewAssetEnricher create new asset enricher
+func NewAssetEnricher(assetService *assets.Service, enrichmentService *enrichment.Service, log logger.ContextLog) *AssetEnricher {
+	return &AssetEnricher{
+		assetService:      assetService,
+		enrichmentService: enrichmentService,
+		log:               log,
+	}
+}
+
+// EnrichAssets fills the missing name, type, currency and exchange of the given tickers, or of all
+// assets when no ticker is given, and flags their currency mismatches
+func (s *AssetEnricher) EnrichAssets(tickers []string) {
+	ctx := context.Background()
+
+	var assets []*entities.Asset
+	var err error
+	if len(tickers) > 0 {
+		assets, err = s.assetService.GetAssetsByTickers(ctx, tickers)
+	} else {
+		assets, err = s.assetService.GetAllAssets(ctx, nil)
+	}
+
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+		return
+	}
+
+	s.enrichAssets(ctx, assets)
+}
+
+// EnrichAssetsFromCheckpoint fills the missing metadata of the next page of assets of the enrich
+// checkpoint and flags their currency mismatches
+func (s *AssetEnricher) EnrichAssetsFromCheckpoint(pageSize int64) {
+	ctx := context.Background()
+
+	assets, err := s.assetService.GetAssetsFromCheckpoint(ctx, consts.ENRICH_CHECKPOINT, pageSize, nil)
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+	}
+
+	s.enrichAssets(ctx, assets)
+
+	if err := s.assetService.ReleaseCheckpoint(ctx, consts.ENRICH_CHECKPOINT, nil); err != nil {
+		s.log.Error(ctx, "release checkpoint failed", "error", err)
+	}
+}
+
+// enrichAssets fills the missing metadata of the assets and flags their currency mismatches
+func (s *AssetEnricher) enrichAssets(ctx context.Context, assets []*entities.Asset) {
+	s.log.Info(ctx, "enriching assets", "numAssets", len(assets))
+	mismatches, failures := s.enrichmentService.EnrichAssets(ctx, assets)
+
+	s.mismatchTickers = append(s.mismatchTickers, mismatches...)
+	for ticker := range failures {
+		s.errorTickers = append(s.errorTickers, ticker)
+	}
+}
+
+// Close enricher
+func (s *AssetEnricher) Close() {
+	s.log.Info(context.Background(), "DONE - ENRICHING ASSETS", "errorTickers", s.errorTickers, "mismatchTickers", s.mismatchTickers)
+}
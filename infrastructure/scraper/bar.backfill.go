@@ -0,0 +1,93 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/bars"
+)
+
+// BarBackfiller struct
+type BarBackfiller struct {
+	barSource    bars.BarSource
+	barService   *bars.Service
+	assetService *assets.Service
+	log          logger.ContextLog
+	errorTickers []string
+}
+
+// NewBarBackfiller create new daily bar backfiller
+func NewBarBackfiller(barSource bars.BarSource, assetService *assets.Service, barService *bars.Service, log logger.ContextLog) *BarBackfiller {
+	return &BarBackfiller{
+		barSource:    barSource,
+		assetService: assetService,
+		barService:   barService,
+		log:          log,
+	}
+}
+
+// Backfill downloads and stores the daily bars between from and to of the given tickers,
+// or of all assets when no ticker is given
+func (s *BarBackfiller) Backfill(from time.Time, to time.Time, tickers []string) {
+	ctx := context.Background()
+
+	var assets []*entities.Asset
+	var err error
+	if len(tickers) > 0 {
+		assets, err = s.assetService.GetAssetsByTickers(ctx, tickers)
+	} else {
+		assets, err = s.assetService.GetAllAssets(ctx, nil)
+	}
+
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+		return
+	}
+
+	s.backfillAssets(ctx, assets, from, to)
+}
+
+// BackfillFromCheckpoint downloads and stores the daily bars between from and to of the next page
+// of assets of the backfill checkpoint
+func (s *BarBackfiller) BackfillFromCheckpoint(from time.Time, to time.Time, pageSize int64) {
+	ctx := context.Background()
+
+	assets, err := s.assetService.GetAssetsFromCheckpoint(ctx, consts.BACKFILL_CHECKPOINT, pageSize, nil)
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+	}
+
+	s.backfillAssets(ctx, assets, from, to)
+
+	if err := s.assetService.ReleaseCheckpoint(ctx, consts.BACKFILL_CHECKPOINT, nil); err != nil {
+		s.log.Error(ctx, "release checkpoint failed", "error", err)
+	}
+}
+
+// backfillAssets downloads and stores the daily bars between from and to of the assets
+func (s *BarBackfiller) backfillAssets(ctx context.Context, assets []*entities.Asset, from time.Time, to time.Time) {
+	for _, asset := range assets {
+		s.log.Info(ctx, "backfilling daily bars", "ticker", asset.Ticker, "from", from, "to", to)
+
+		priceBars, err := s.barSource.FetchDailyBars(ctx, asset, from.Unix(), to.Unix())
+		if err != nil {
+			s.log.Error(ctx, "fetch daily bars failed", "error", err, "ticker", asset.Ticker)
+			s.errorTickers = append(s.errorTickers, asset.Ticker)
+			continue
+		}
+
+		if err := s.barService.AddPriceBars(ctx, priceBars); err != nil {
+			s.log.Error(ctx, "add daily bars failed", "error", err, "ticker", asset.Ticker)
+			s.errorTickers = append(s.errorTickers, asset.Ticker)
+		}
+	}
+}
+
+// Close backfiller
+func (s *BarBackfiller) Close() {
+	s.log.Info(context.Background(), "DONE - BACKFILLING DAILY BARS", "errorTickers", s.errorTickers)
+}
@@ -0,0 +1,126 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+// DividendScraper struct
+type DividendScraper struct {
+	eventSource  events.EventSource
+	eventService *events.Service
+	assetService *assets.Service
+	priceService *price.Service
+	log          logger.ContextLog
+	errorTickers []string
+}
+
+// NewDividendScraper create new dividend and split event scraper
+func NewDividendScraper(eventSource events.EventSource, assetService *assets.Service, priceService *price.Service, eventService *events.Service, log logger.ContextLog) *DividendScraper {
+	return &DividendScraper{
+		eventSource:  eventSource,
+		assetService: assetService,
+		priceService: priceService,
+		eventService: eventService,
+		log:          log,
+	}
+}
+
+// ScrapeDividends scrapes the dividend and split events since from of the given tickers, or of
+// all assets when no ticker is given, then recomputes the distribution figures of each asset
+func (s *DividendScraper) ScrapeDividends(from time.Time, tickers []string) {
+	ctx := context.Background()
+
+	var assets []*entities.Asset
+	var err error
+	if len(tickers) > 0 {
+		assets, err = s.assetService.GetAssetsByTickers(ctx, tickers)
+	} else {
+		assets, err = s.assetService.GetAllAssets(ctx, nil)
+	}
+
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+		return
+	}
+
+	s.scrapeDividends(ctx, assets, from)
+}
+
+// ScrapeDividendsFromCheckpoint scrapes the dividend and split events since from of the next page
+// of assets of the dividends checkpoint
+func (s *DividendScraper) ScrapeDividendsFromCheckpoint(from time.Time, pageSize int64) {
+	ctx := context.Background()
+
+	assets, err := s.assetService.GetAssetsFromCheckpoint(ctx, consts.DIVIDENDS_CHECKPOINT, pageSize, nil)
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+	}
+
+	s.scrapeDividends(ctx, assets, from)
+
+	if err := s.assetService.ReleaseCheckpoint(ctx, consts.DIVIDENDS_CHECKPOINT, nil); err != nil {
+		s.log.Error(ctx, "release checkpoint failed", "error", err)
+	}
+}
+
+// scrapeDividends scrapes the events of the assets and updates their distribution figures
+func (s *DividendScraper) scrapeDividends(ctx context.Context, assets []*entities.Asset, from time.Time) {
+	for _, asset := range assets {
+		if err := s.scrapeAssetDividends(ctx, asset, from, time.Now().UTC()); err != nil {
+			s.errorTickers = append(s.errorTickers, asset.Ticker)
+		}
+	}
+}
+
+// scrapeAssetDividends stores the events of an asset and updates its distribution figures
+func (s *DividendScraper) scrapeAssetDividends(ctx context.Context, asset *entities.Asset, from time.Time, now time.Time) error {
+	s.log.Info(ctx, "scraping dividend events", "ticker", asset.Ticker, "from", from)
+
+	assetEvents, err := s.eventSource.FetchEvents(ctx, asset, from.Unix(), now.Unix())
+	if err != nil {
+		s.log.Error(ctx, "fetch events failed", "error", err, "ticker", asset.Ticker)
+		return err
+	}
+
+	if err := s.eventService.AddAssetEvents(ctx, assetEvents); err != nil {
+		s.log.Error(ctx, "add events failed", "error", err, "ticker", asset.Ticker)
+		return err
+	}
+
+	assetPrice, err := s.priceService.GetAssetPrice(ctx, asset.Ticker)
+	if err != nil {
+		s.log.Error(ctx, "get asset price failed", "error", err, "ticker", asset.Ticker)
+		return err
+	}
+
+	if assetPrice == nil || assetPrice.Price <= 0 {
+		s.log.Info(ctx, "skip distribution update, asset has no price yet", "ticker", asset.Ticker)
+		return nil
+	}
+
+	distribution, err := s.eventService.GetTrailingDistribution(ctx, asset.Ticker, assetPrice.Price, now)
+	if err != nil {
+		s.log.Error(ctx, "compute distribution failed", "error", err, "ticker", asset.Ticker)
+		return err
+	}
+
+	if err := s.assetService.UpdateAssetDistribution(ctx, asset.Ticker, distribution); err != nil {
+		s.log.Error(ctx, "update asset distribution failed", "error", err, "ticker", asset.Ticker)
+		return err
+	}
+
+	return nil
+}
+
+// Close scraper
+func (s *DividendScraper) Close() {
+	s.log.Info(context.Background(), "DONE - SCRAPING DIVIDEND EVENTS", "errorTickers", s.errorTickers)
+}
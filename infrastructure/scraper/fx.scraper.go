@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/fx"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+// FxScraper struct
+type FxScraper struct {
+	source       price.PriceSource
+	assetService *assets.Service
+	fxService    *fx.Service
+	log          logger.ContextLog
+	errorPairs   []string
+}
+
+// NewFxScraper create new fx rate scraper
+func NewFxScraper(source price.PriceSource, assetService *assets.Service, fxService *fx.Service, log logger.ContextLog) *FxScraper {
+	return &FxScraper{
+		source:       source,
+		assetService: assetService,
+		fxService:    fxService,
+		log:          log,
+	}
+}
+
+// ScrapeFxRates scrapes the rates of the given currencies to the base currency, or of the
+// currencies of all assets when none is given. Pairs are fetched from the price source like assets
+func (s *FxScraper) ScrapeFxRates(currencies []string) {
+	ctx := context.Background()
+
+	if len(currencies) == 0 {
+		assets, err := s.assetService.GetAllAssets(ctx, nil)
+		if err != nil {
+			s.log.Error(ctx, "get assets list failed", "error", err)
+			return
+		}
+
+		for _, asset := range assets {
+			currencies = append(currencies, asset.Currency)
+		}
+	}
+
+	base := s.fxService.BaseCurrency()
+
+	// pairs are scraped as assets whose ticker is the pair symbol
+	pairs := map[string]string{}
+	var pairAssets []*entities.Asset
+	for _, currency := range currencies {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if currency == "" || currency == base {
+			continue
+		}
+
+		pair := fx.PairSymbol(currency, base)
+		if _, ok := pairs[pair]; ok {
+			continue
+		}
+
+		pairs[pair] = currency
+		pairAssets = append(pairAssets, &entities.Asset{
+			Ticker:      pair,
+			YahooSymbol: pair,
+			Currency:    base,
+		})
+	}
+
+	if len(pairAssets) == 0 {
+		s.log.Info(ctx, "no fx pairs to scrape", "baseCurrency", base)
+		return
+	}
+
+	s.log.Info(ctx, "scraping fx rates", "source", s.source.Name(), "numPairs", len(pairAssets))
+	pairPrices, failures := s.source.FetchAssetPrices(ctx, pairAssets)
+
+	for pair, err := range failures {
+		s.log.Error(ctx, "fetch fx rate failed", "error", err, "pair", pair)
+		s.errorPairs = append(s.errorPairs, pair)
+	}
+
+	var fxRates []*entities.FxRate
+	for _, pairPrice := range pairPrices {
+		fxRates = append(fxRates, &entities.FxRate{
+			Pair:       pairPrice.Ticker,
+			From:       pairs[pairPrice.Ticker],
+			To:         base,
+			Rate:       pairPrice.Price,
+			MarketTime: pairPrice.MarketTime,
+			Source:     pairPrice.Source,
+		})
+	}
+
+	if err := s.fxService.AddFxRates(ctx, fxRates); err != nil {
+		s.log.Error(ctx, "add fx rates failed", "error", err)
+	}
+}
+
+// Close scraper
+func (s *FxScraper) Close() {
+	s.log.Info(context.Background(), "DONE - SCRAPING FX RATES", "errorPairs", s.errorPairs)
+}
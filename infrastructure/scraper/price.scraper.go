@@ -2,186 +2,431 @@ package scraper
 
 import (
 	"context"
-	"strconv"
+	"errors"
+	"math/rand"
 	"strings"
 	"time"
 
-	"github.com/gocolly/colly"
-	"github.com/gocolly/colly/extensions"
-	"github.com/google/uuid"
-	corid "github.com/lenoobz/aws-lambda-corid"
 	logger "github.com/lenoobz/aws-lambda-logger"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/deadletter"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/fx"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/retries"
 )
 
 // PriceScraper struct
 type PriceScraper struct {
-	ScrapePriceJob *colly.Collector
+	source         price.PriceSource
 	priceService   *price.Service
 	assetService   *assets.Service
+	retryService   *retries.Service
+	failureService *deadletter.Service
+	eventService   *events.Service
+	fxService      *fx.Service
 	log            logger.ContextLog
 	errorTickers   []string
+	// maxRetries is the number of times failed tickers are retried within a run
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	jitter         *rand.Rand
+	// extendedHoursOnly only stores pre-market and after-hours prices of quotes outside regular hours
+	extendedHoursOnly bool
+	// assetFilter selects the scraped assets, the enabled assets not deleted when nil
+	assetFilter *entities.AssetFilter
 }
 
 // NewAssetPriceScraper create new price scraper
-func NewAssetPriceScraper(assetService *assets.Service, priceService *price.Service, log logger.ContextLog) *PriceScraper {
-	scrapePriceJob := newScraperJob()
-
+func NewAssetPriceScraper(source price.PriceSource, assetService *assets.Service, priceService *price.Service, retryService *retries.Service, failureService *deadletter.Service, eventService *events.Service, fxService *fx.Service, conf *config.ScraperConfig, log logger.ContextLog) *PriceScraper {
 	return &PriceScraper{
-		ScrapePriceJob: scrapePriceJob,
+		source:         source,
 		assetService:   assetService,
 		priceService:   priceService,
+		retryService:   retryService,
+		failureService: failureService,
+		eventService:   eventService,
+		fxService:      fxService,
 		log:            log,
+		maxRetries:     conf.MaxRetries,
+		retryBaseDelay: time.Duration(conf.RetryBaseDelayMS) * time.Millisecond,
+		retryMaxDelay:  time.Duration(conf.RetryMaxDelayMS) * time.Millisecond,
+		jitter:         rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
-// newScraperJob creates a new colly collector with some custom configs
-func newScraperJob() *colly.Collector {
-	c := colly.NewCollector(
-		colly.AllowedDomains(config.AllowDomain),
-		colly.Async(true),
-	)
+// SetExtendedHoursOnly switches the scraper to the extended hours mode. Quotes in regular
+// hours are skipped and only the pre-market and after-hours fields of the others are stored
+func (s *PriceScraper) SetExtendedHoursOnly(extendedHoursOnly bool) {
+	s.extendedHoursOnly = extendedHoursOnly
+}
 
-	// Overrides the default timeout (10 seconds) for this collector
-	c.SetRequestTimeout(30 * time.Second)
+// SetAssetFilter limits both scrape modes to the assets matching the filter
+func (s *PriceScraper) SetAssetFilter(filter *entities.AssetFilter) {
+	s.assetFilter = filter
+}
 
-	// Limit the number of threads started by colly to two
-	// when visiting links which domains' matches "*httpbin.*" glob
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  config.DomainGlob,
-		Parallelism: 2,
-		RandomDelay: 2 * time.Second,
-	})
+// ScrapeAllAssetPrices scrape all assets price
+func (s *PriceScraper) ScrapeAllAssetPrices() {
+	ctx := context.Background()
 
-	extensions.RandomUserAgent(c)
-	extensions.Referer(c)
+	assets, err := s.assetService.GetAllAssets(ctx, s.assetFilter)
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+	}
 
-	return c
+	s.scrapeAssetPrices(ctx, assets)
 }
 
-// configJobs configs on error handler and on response handler for scaper jobs
-func (s *PriceScraper) configJobs() {
-	s.ScrapePriceJob.OnError(s.errorHandler)
-	s.ScrapePriceJob.OnScraped(s.scrapedHandler)
-	s.ScrapePriceJob.OnHTML("div[id=quote-header-info]", s.processPriceResponse)
+// ScrapeAssetPricesFromCheckpoint scrape all assets price from checkpoint. Tickers queued for retry
+// by previous runs are scraped before the checkpoint leases the next page, those on the page are
+// not scraped again. The page stays leased to this run until it is scraped, a run that does not
+// finish leaves it to be reclaimed
+func (s *PriceScraper) ScrapeAssetPricesFromCheckpoint(pageSize int64) {
+	ctx := context.Background()
+
+	retried := s.scrapeQueuedRetries(ctx, pageSize)
+
+	assets, err := s.assetService.GetAssetsFromCheckpoint(ctx, consts.PRICES_CHECKPOINT, pageSize, s.assetFilter)
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+	}
+
+	var pageAssets []*entities.Asset
+	for _, asset := range assets {
+		if !retried[strings.ToUpper(asset.Ticker)] {
+			pageAssets = append(pageAssets, asset)
+		}
+	}
+
+	s.scrapeAssetPrices(ctx, pageAssets)
+
+	if err := s.assetService.ReleaseCheckpoint(ctx, consts.PRICES_CHECKPOINT, s.assetFilter); err != nil {
+		s.log.Error(ctx, "release checkpoint failed", "error", err)
+	}
 }
 
-// ScrapeAllAssetPrices scrape all assets price
-func (s *PriceScraper) ScrapeAllAssetPrices() {
-	ctx := context.Background()
+// scrapeQueuedRetries scrapes the oldest tickers queued for retry matching the asset filter, at
+// most limit of them. Returns the scraped tickers, upper cased
+func (s *PriceScraper) scrapeQueuedRetries(ctx context.Context, limit int64) map[string]bool {
+	queued, err := s.retryService.GetScrapeRetries(ctx, limit)
+	if err != nil {
+		s.log.Error(ctx, "get scrape retries failed", "error", err)
+		return nil
+	}
 
-	s.configJobs()
+	if len(queued) == 0 {
+		return nil
+	}
 
-	assets, err := s.assetService.GetAllAssets(ctx)
+	var tickers []string
+	for _, retry := range queued {
+		tickers = append(tickers, retry.Ticker)
+	}
+
+	assets, err := s.assetService.GetAssetsByTickers(ctx, tickers)
 	if err != nil {
 		s.log.Error(ctx, "get assets list failed", "error", err)
+		return nil
 	}
 
+	// tickers that are no longer assets do not need to be retried
+	found := map[string]bool{}
 	for _, asset := range assets {
-		reqContext := colly.NewContext()
-		reqContext.Put("ticker", asset.Ticker)
-		reqContext.Put("currency", asset.Currency)
+		found[strings.ToUpper(asset.Ticker)] = true
+	}
 
-		url := config.GetPriceByTickerURL(asset.Ticker)
+	var removed []string
+	for _, ticker := range tickers {
+		if !found[strings.ToUpper(ticker)] {
+			removed = append(removed, ticker)
+		}
+	}
 
-		s.log.Info(ctx, "scraping asset price", "ticker", asset.Ticker)
-		if err := s.ScrapePriceJob.Request("GET", url, nil, reqContext, nil); err != nil {
-			s.log.Error(ctx, "scraping asset price", "error", err, "ticker", asset.Ticker)
+	if len(removed) > 0 {
+		if err := s.retryService.RemoveScrapeRetries(ctx, removed); err != nil {
+			s.log.Error(ctx, "remove scrape retries failed", "error", err)
 		}
 	}
 
-	s.ScrapePriceJob.Wait()
+	// a filtered run only retries the queued tickers matching its filter, the others stay queued
+	if s.assetFilter != nil {
+		filter := *s.assetFilter
+		filter.Tickers = intersectTickers(tickers, s.assetFilter.Tickers)
+		if len(filter.Tickers) == 0 {
+			return nil
+		}
+
+		if assets, err = s.assetService.GetAllAssets(ctx, &filter); err != nil {
+			s.log.Error(ctx, "get assets list failed", "error", err)
+			return nil
+		}
+	}
+
+	s.log.Info(ctx, "scraping queued retries", "numAssets", len(assets))
+	s.scrapeAssetPrices(ctx, assets)
+
+	scraped := map[string]bool{}
+	for _, asset := range assets {
+		scraped[strings.ToUpper(asset.Ticker)] = true
+	}
+
+	return scraped
 }
 
-// ScrapeAssetPricesFromCheckpoint scrape all assets price from checkpoint
-func (s *PriceScraper) ScrapeAssetPricesFromCheckpoint(pageSize int64) {
-	ctx := context.Background()
+// scrapeAssetPrices fetches prices of the given assets from the price source and stores them.
+// Failed tickers are retried with a jittered exponential backoff, those still failing after the
+// last retry are recorded as failures and queued for the next run unless they got quarantined.
+// Tickers yahoo does not know are permanent failures, they are recorded but never retried.
+// The others are removed from the queue and their consecutive failures are reset
+func (s *PriceScraper) scrapeAssetPrices(ctx context.Context, assets []*entities.Asset) {
+	if len(assets) == 0 {
+		return
+	}
 
-	s.configJobs()
+	failures := s.fetchAndSavePrices(ctx, assets)
 
-	assets, err := s.assetService.GetAssetsFromCheckpoint(ctx, pageSize)
-	if err != nil {
-		s.log.Error(ctx, "get assets list failed", "error", err)
+	for attempt := 0; attempt < s.maxRetries && len(failures) > 0; attempt++ {
+		var failedAssets []*entities.Asset
+		for _, asset := range assets {
+			if err, failed := failures[asset.Ticker]; failed && !isPermanentFailure(err) {
+				failedAssets = append(failedAssets, asset)
+			}
+		}
+
+		if len(failedAssets) == 0 {
+			break
+		}
+
+		delay := s.backoffDelay(attempt)
+		s.log.Info(ctx, "retrying failed tickers", "attempt", attempt+1, "numAssets", len(failedAssets), "delay", delay.String())
+		time.Sleep(delay)
+
+		// only the retried tickers are fetched again, the other failures stand
+		retried := s.fetchAndSavePrices(ctx, failedAssets)
+		for _, asset := range failedAssets {
+			if err, failed := retried[asset.Ticker]; failed {
+				failures[asset.Ticker] = err
+			} else {
+				delete(failures, asset.Ticker)
+			}
+		}
 	}
 
+	var succeeded []string
 	for _, asset := range assets {
-		reqContext := colly.NewContext()
-		reqContext.Put("ticker", asset.Ticker)
-		reqContext.Put("currency", asset.Currency)
+		if _, failed := failures[asset.Ticker]; !failed {
+			succeeded = append(succeeded, asset.Ticker)
+		}
+	}
 
-		url := config.GetPriceByTickerURL(asset.Ticker)
+	if err := s.retryService.RemoveScrapeRetries(ctx, succeeded); err != nil {
+		s.log.Error(ctx, "remove scrape retries failed", "error", err)
+	}
 
-		s.log.Info(ctx, "scraping asset price", "ticker", asset.Ticker)
-		if err := s.ScrapePriceJob.Request("GET", url, nil, reqContext, nil); err != nil {
-			s.log.Error(ctx, "scraping asset price failed", "error", err, "ticker", asset.Ticker)
+	if err := s.failureService.ClearFailures(ctx, succeeded); err != nil {
+		s.log.Error(ctx, "clear scrape failures failed", "error", err)
+	}
+
+	if len(failures) == 0 {
+		return
+	}
+
+	for ticker, err := range failures {
+		s.log.Error(ctx, "scrape price failed", "error", err, "ticker", ticker)
+		s.errorTickers = append(s.errorTickers, ticker)
+	}
+
+	quarantined, err := s.failureService.RecordFailures(ctx, failures)
+	if err != nil {
+		s.log.Error(ctx, "record scrape failures failed", "error", err)
+	}
+
+	// quarantined tickers are not scraped anymore and permanent failures would fail again, there
+	// is nothing to retry
+	var unretried []string
+	retries := map[string]error{}
+	for ticker, err := range failures {
+		if isPermanentFailure(err) {
+			unretried = append(unretried, ticker)
+			continue
+		}
+		retries[ticker] = err
+	}
+
+	if len(quarantined) > 0 {
+		s.log.Info(ctx, "quarantined tickers", "tickers", quarantined)
+		for _, ticker := range quarantined {
+			if _, ok := retries[ticker]; ok {
+				delete(retries, ticker)
+				unretried = append(unretried, ticker)
+			}
+		}
+	}
+
+	if len(unretried) > 0 {
+		if err := s.retryService.RemoveScrapeRetries(ctx, unretried); err != nil {
+			s.log.Error(ctx, "remove scrape retries failed", "error", err)
 		}
 	}
 
-	s.ScrapePriceJob.Wait()
+	if len(retries) == 0 {
+		return
+	}
+
+	if err := s.retryService.AddScrapeRetries(ctx, retries); err != nil {
+		s.log.Error(ctx, "add scrape retries failed", "error", err)
+	}
 }
 
-///////////////////////////////////////////////////////////
-// Scraper Handler
-///////////////////////////////////////////////////////////
+// fetchAndSavePrices fetches prices of the given assets from the price source and stores them,
+// returns the error of each ticker that failed
+func (s *PriceScraper) fetchAndSavePrices(ctx context.Context, assets []*entities.Asset) map[string]error {
+	s.log.Info(ctx, "scraping asset prices", "source", s.source.Name(), "numAssets", len(assets))
+	assetPrices, failures := s.source.FetchAssetPrices(ctx, assets)
+	if failures == nil {
+		failures = map[string]error{}
+	}
 
-// errorHandler generic error handler for all scaper jobs
-func (s *PriceScraper) errorHandler(r *colly.Response, err error) {
-	ctx := context.Background()
-	s.log.Error(ctx, "failed to request url", "url", r.Request.URL, "error", err)
-	s.errorTickers = append(s.errorTickers, r.Request.Ctx.Get("ticker"))
+	for ticker, err := range failures {
+		s.log.Error(ctx, "fetch price failed", "error", err, "ticker", ticker)
+	}
+
+	for _, assetPrice := range assetPrices {
+		s.convertToBase(ctx, assetPrice)
+
+		if err := s.savePrice(ctx, assetPrice); err != nil {
+			s.log.Error(ctx, "add price failed", "error", err, "ticker", assetPrice.Ticker)
+			failures[assetPrice.Ticker] = &price.FetchError{Class: consts.STORE_ERROR, Err: err}
+		}
+	}
+
+	s.recordCurrencyDiscrepancies(ctx, assets, assetPrices)
+
+	return failures
 }
 
-func (s *PriceScraper) scrapedHandler(r *colly.Response) {
-	ctx := context.Background()
-	foundPrice := r.Ctx.Get("foundPrice")
-	if foundPrice == "" {
-		s.log.Error(ctx, "price not found", "ticker", r.Request.Ctx.Get("ticker"))
-		s.errorTickers = append(s.errorTickers, r.Request.Ctx.Get("ticker"))
+// convertToBase sets the price in the base currency, it is left empty when there is no rate for
+// the price currency or it is too old. Prices without a currency, like the points of an index, are not converted
+func (s *PriceScraper) convertToBase(ctx context.Context, assetPrice *entities.AssetPrice) {
+	if assetPrice.Currency == "" {
+		return
+	}
+
+	priceInBase, ok := s.fxService.ToBase(ctx, assetPrice.Price, assetPrice.Currency)
+	if !ok {
+		s.log.Error(ctx, "no fx rate for price currency", "ticker", assetPrice.Ticker, "currency", assetPrice.Currency, "baseCurrency", s.fxService.BaseCurrency())
+		return
 	}
+
+	assetPrice.PriceInBase = priceInBase
+	assetPrice.BaseCurrency = s.fxService.BaseCurrency()
 }
 
-func (s *PriceScraper) processPriceResponse(e *colly.HTMLElement) {
-	// create correlation if for processing fund list
-	id, _ := uuid.NewRandom()
-	ctx := corid.NewContext(context.Background(), id)
+// recordCurrencyDiscrepancies records an event for each fetched price whose currency differs from
+// the currency of its asset, at most one per ticker and day
+func (s *PriceScraper) recordCurrencyDiscrepancies(ctx context.Context, assets []*entities.Asset, assetPrices []*entities.AssetPrice) {
+	assetsByTicker := map[string]*entities.Asset{}
+	for _, asset := range assets {
+		assetsByTicker[asset.Ticker] = asset
+	}
 
-	ticker := e.Request.Ctx.Get("ticker")
-	currency := e.Request.Ctx.Get("currency")
-	s.log.Info(ctx, "processPriceResponse", "ticker", ticker)
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()
 
-	foundPrice := false
+	var discrepancies []*entities.AssetEvent
+	for _, assetPrice := range assetPrices {
+		asset, ok := assetsByTicker[assetPrice.Ticker]
+		if !ok || asset.Currency == "" || assetPrice.Currency == "" || strings.EqualFold(asset.Currency, assetPrice.Currency) {
+			continue
+		}
 
-	assetPrice := entities.AssetPrice{
-		Ticker:   ticker,
-		Currency: currency,
+		s.log.Error(ctx, "currency discrepancy", "ticker", asset.Ticker, "currency", asset.Currency, "reportedCurrency", assetPrice.Currency)
+		discrepancies = append(discrepancies, &entities.AssetEvent{
+			Ticker:           asset.Ticker,
+			Type:             consts.CURRENCY_DISCREPANCY_EVENT,
+			Date:             today,
+			Currency:         strings.ToUpper(assetPrice.Currency),
+			ExpectedCurrency: strings.ToUpper(asset.Currency),
+			Source:           assetPrice.Source,
+		})
 	}
 
-	e.ForEach("span", func(_ int, span *colly.HTMLElement) {
-		txt := span.Attr("data-reactid")
-		if strings.EqualFold(txt, "31") {
-			p := strings.Replace(span.DOM.Text(), ",", "", -1)
+	if len(discrepancies) == 0 {
+		return
+	}
 
-			val, err := strconv.ParseFloat(p, 64)
-			if err != nil {
-				s.log.Error(ctx, "parse price failed", "error", err, "ticker", ticker, "raw-value", txt)
-				return
-			}
+	if err := s.eventService.AddAssetEvents(ctx, discrepancies); err != nil {
+		s.log.Error(ctx, "add currency discrepancy events failed", "error", err)
+	}
+}
 
-			assetPrice.Price = val
-			foundPrice = true
-		}
-	})
+// isPermanentFailure reports whether a ticker failed because yahoo does not know it, retrying
+// it would fail the same way
+func isPermanentFailure(err error) bool {
+	var fetchErr *price.FetchError
+	return errors.As(err, &fetchErr) && fetchErr.IsDelisted()
+}
 
-	if foundPrice {
-		e.Response.Ctx.Put("foundPrice", "true")
+// intersectTickers returns the tickers also listed in filterTickers, all of them when
+// filterTickers is empty
+func intersectTickers(tickers []string, filterTickers []string) []string {
+	if len(filterTickers) == 0 {
+		return tickers
+	}
 
-		if err := s.priceService.AddAssetPrice(ctx, &assetPrice); err != nil {
-			s.log.Error(ctx, "add price failed", "error", err, "ticker", ticker)
+	listed := map[string]bool{}
+	for _, ticker := range filterTickers {
+		listed[strings.ToUpper(ticker)] = true
+	}
+
+	var matched []string
+	for _, ticker := range tickers {
+		if listed[strings.ToUpper(ticker)] {
+			matched = append(matched, ticker)
 		}
 	}
+
+	return matched
+}
+
+// backoffDelay returns the delay before the retry attempt (zero based). The delay doubles with
+// each attempt up to the max delay, and is jittered between half of it and all of it
+func (s *PriceScraper) backoffDelay(attempt int) time.Duration {
+	delay := s.retryBaseDelay << uint(attempt)
+	if delay <= 0 || delay > s.retryMaxDelay {
+		delay = s.retryMaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(s.jitter.Int63n(int64(delay-half)+1))
+}
+
+// savePrice stores a fetched price according to the scrape mode
+func (s *PriceScraper) savePrice(ctx context.Context, assetPrice *entities.AssetPrice) error {
+	if !s.extendedHoursOnly {
+		return s.priceService.AddAssetPrice(ctx, assetPrice)
+	}
+
+	if assetPrice.MarketState == consts.MARKET_STATE_REGULAR {
+		s.log.Info(ctx, "skip extended hours price, market is in regular hours", "ticker", assetPrice.Ticker)
+		return nil
+	}
+
+	if !assetPrice.HasExtendedHoursPrice() {
+		s.log.Info(ctx, "skip extended hours price, quote has no extended hours price", "ticker", assetPrice.Ticker)
+		return nil
+	}
+
+	return s.priceService.AddExtendedHoursPrice(ctx, assetPrice)
 }
 
 // Close scraper
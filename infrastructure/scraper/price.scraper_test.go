@@ -0,0 +1,436 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/deadletter"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/fx"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/retries"
+)
+
+// scriptedSource fails each fetch of a ticker with the next error scripted for it, and returns a
+// price once its errors run out
+type scriptedSource struct {
+	errs    map[string][]error
+	fetches map[string]int
+}
+
+func (s *scriptedSource) Name() string {
+	return "scripted"
+}
+
+func (s *scriptedSource) FetchAssetPrices(ctx context.Context, assets []*entities.Asset) ([]*entities.AssetPrice, map[string]error) {
+	var prices []*entities.AssetPrice
+	failures := map[string]error{}
+	for _, asset := range assets {
+		fetch := s.fetches[asset.Ticker]
+		s.fetches[asset.Ticker]++
+
+		if errs := s.errs[asset.Ticker]; fetch < len(errs) && errs[fetch] != nil {
+			failures[asset.Ticker] = errs[fetch]
+			continue
+		}
+
+		prices = append(prices, &entities.AssetPrice{Ticker: asset.Ticker, Price: 10})
+	}
+
+	return prices, failures
+}
+
+// scraperStore is the state the fake repos of a scraper share
+type scraperStore struct {
+	assets []*entities.Asset
+	// page is the page of assets the checkpoint leases
+	page        []*entities.Asset
+	queued      map[string]bool
+	failures    map[string]int64
+	delisted    map[string]bool
+	quarantined map[string]bool
+	// trace lists the retry queue reads and checkpoint leases and releases in order
+	trace []string
+}
+
+func newScraperStore(tickers ...string) *scraperStore {
+	store := &scraperStore{
+		queued:      map[string]bool{},
+		failures:    map[string]int64{},
+		delisted:    map[string]bool{},
+		quarantined: map[string]bool{},
+	}
+
+	for _, ticker := range tickers {
+		store.assets = append(store.assets, &entities.Asset{Ticker: ticker})
+	}
+
+	return store
+}
+
+func (s *scraperStore) findAssets(tickers []string) []*entities.Asset {
+	var found []*entities.Asset
+	for _, asset := range s.assets {
+		for _, ticker := range tickers {
+			if strings.EqualFold(asset.Ticker, ticker) {
+				found = append(found, asset)
+			}
+		}
+	}
+
+	return found
+}
+
+type fakeScraperAssetRepo struct {
+	assets.Repo
+	store *scraperStore
+}
+
+func (r *fakeScraperAssetRepo) CountAssets(ctx context.Context, filter *entities.AssetFilter) (int64, error) {
+	return int64(len(r.store.assets)), nil
+}
+
+func (r *fakeScraperAssetRepo) FindAllAssets(ctx context.Context, filter *entities.AssetFilter) ([]*entities.Asset, error) {
+	if filter == nil || len(filter.Tickers) == 0 {
+		return r.store.assets, nil
+	}
+
+	return r.store.findAssets(filter.Tickers), nil
+}
+
+func (r *fakeScraperAssetRepo) FindAssetsByTickers(ctx context.Context, tickers []string) ([]*entities.Asset, error) {
+	return r.store.findAssets(tickers), nil
+}
+
+func (r *fakeScraperAssetRepo) FindAssetsFromCheckpoint(ctx context.Context, checkpoint *entities.Checkpoint, filter *entities.AssetFilter) ([]*entities.Asset, error) {
+	return r.store.page, nil
+}
+
+func (r *fakeScraperAssetRepo) FlagAssetDelisted(ctx context.Context, ticker string, successor string) error {
+	r.store.delisted[ticker] = true
+	return nil
+}
+
+func (r *fakeScraperAssetRepo) ClearAssetDelisted(ctx context.Context, tickers []string) error {
+	for _, ticker := range tickers {
+		delete(r.store.delisted, ticker)
+	}
+	return nil
+}
+
+func (r *fakeScraperAssetRepo) QuarantineAsset(ctx context.Context, ticker string, reason string) error {
+	r.store.quarantined[ticker] = true
+	return nil
+}
+
+type fakeScraperCheckpointRepo struct {
+	checkpoint.Repo
+	store *scraperStore
+}
+
+func (r *fakeScraperCheckpointRepo) LeaseCheckpoint(ctx context.Context, name string, pageSize int64, numAssets int64, owner string, leaseMS uint64) (*entities.Checkpoint, error) {
+	r.store.trace = append(r.store.trace, "lease")
+	return &entities.Checkpoint{Name: name, PageSize: pageSize, Owner: owner}, nil
+}
+
+func (r *fakeScraperCheckpointRepo) ReleaseCheckpoint(ctx context.Context, name string, owner string) error {
+	r.store.trace = append(r.store.trace, "release")
+	return nil
+}
+
+type fakeScraperRetryRepo struct {
+	retries.Repo
+	store *scraperStore
+}
+
+func (r *fakeScraperRetryRepo) FindScrapeRetries(ctx context.Context, limit int64) ([]*entities.ScrapeRetry, error) {
+	r.store.trace = append(r.store.trace, "retries")
+
+	var tickers []string
+	for ticker := range r.store.queued {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	var queued []*entities.ScrapeRetry
+	for _, ticker := range tickers {
+		queued = append(queued, &entities.ScrapeRetry{Ticker: ticker})
+	}
+
+	return queued, nil
+}
+
+func (r *fakeScraperRetryRepo) InsertScrapeRetries(ctx context.Context, retries []*entities.ScrapeRetry) error {
+	for _, retry := range retries {
+		r.store.queued[retry.Ticker] = true
+	}
+	return nil
+}
+
+func (r *fakeScraperRetryRepo) DeleteScrapeRetries(ctx context.Context, tickers []string) error {
+	for _, ticker := range tickers {
+		delete(r.store.queued, ticker)
+	}
+	return nil
+}
+
+type fakeScraperFailureRepo struct {
+	deadletter.Repo
+	store *scraperStore
+}
+
+func (r *fakeScraperFailureRepo) UpsertScrapeFailure(ctx context.Context, failure *entities.ScrapeFailure) (*entities.ScrapeFailure, error) {
+	r.store.failures[failure.Ticker]++
+
+	stored := *failure
+	stored.ConsecutiveFailures = r.store.failures[failure.Ticker]
+	return &stored, nil
+}
+
+func (r *fakeScraperFailureRepo) DeleteScrapeFailures(ctx context.Context, tickers []string) error {
+	for _, ticker := range tickers {
+		delete(r.store.failures, ticker)
+	}
+	return nil
+}
+
+type fakeScraperPriceRepo struct {
+	price.Repo
+}
+
+func (r *fakeScraperPriceRepo) InsertAssetPrice(ctx context.Context, assetPrice *entities.AssetPrice) error {
+	return nil
+}
+
+func (r *fakeScraperPriceRepo) InsertAssetPriceHistory(ctx context.Context, assetPrice *entities.AssetPrice) error {
+	return nil
+}
+
+type fakeScraperEventRepo struct {
+	events.Repo
+}
+
+func (r *fakeScraperEventRepo) InsertAssetEvents(ctx context.Context, events []*entities.AssetEvent) error {
+	return nil
+}
+
+type fakeScraperFxRepo struct {
+	fx.Repo
+}
+
+func (r *fakeScraperFxRepo) FindFxRates(ctx context.Context, to string) ([]*entities.FxRate, error) {
+	return nil, nil
+}
+
+// newTestPriceScraper creates a price scraper of the source over the fake repos of the store,
+// retrying failed tickers without delay
+func newTestPriceScraper(t *testing.T, source price.PriceSource, store *scraperStore, maxRetries int, quarantineThreshold int64) *PriceScraper {
+	t.Helper()
+
+	log := newTestLogger(t)
+
+	checkpointService := checkpoint.NewService(&fakeScraperCheckpointRepo{store: store}, 60000, log)
+	assetService := assets.NewService(&fakeScraperAssetRepo{store: store}, *checkpointService, log)
+	priceService := price.NewService(&fakeScraperPriceRepo{}, log)
+	retryService := retries.NewService(&fakeScraperRetryRepo{store: store}, log)
+	failureService := deadletter.NewService(&fakeScraperFailureRepo{store: store}, assetService, quarantineThreshold, log)
+	eventService := events.NewService(&fakeScraperEventRepo{}, log)
+	fxService := fx.NewService(&fakeScraperFxRepo{}, "CAD", 0, log)
+
+	conf := &config.ScraperConfig{MaxRetries: maxRetries}
+	return NewAssetPriceScraper(source, assetService, priceService, retryService, failureService, eventService, fxService, conf, log)
+}
+
+// failEvery returns the error n times
+func failEvery(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+func TestBackoffDelay(t *testing.T) {
+	s := &PriceScraper{
+		retryBaseDelay: 100 * time.Millisecond,
+		retryMaxDelay:  time.Second,
+		jitter:         rand.New(rand.NewSource(1)),
+	}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 0, max: 100 * time.Millisecond},
+		{attempt: 1, max: 200 * time.Millisecond},
+		{attempt: 2, max: 400 * time.Millisecond},
+		{attempt: 3, max: 800 * time.Millisecond},
+		// capped at the max delay
+		{attempt: 4, max: time.Second},
+		// the shift overflows
+		{attempt: 80, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			got := s.backoffDelay(tt.attempt)
+			if got < tt.max/2 || got > tt.max {
+				t.Fatalf("backoffDelay(%d) = %v, want between %v and %v", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+
+	s.retryBaseDelay = 0
+	s.retryMaxDelay = 0
+	if got := s.backoffDelay(3); got != 0 {
+		t.Errorf("backoffDelay() without delays = %v, want 0", got)
+	}
+}
+
+func TestIsPermanentFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: &price.FetchError{Class: consts.NOT_FOUND_ERROR}, want: true},
+		{err: &price.FetchError{Class: consts.LOOKUP_ERROR}, want: true},
+		{err: fmt.Errorf("wrapped: %w", &price.FetchError{Class: consts.RENAMED_ERROR}), want: true},
+		{err: &price.FetchError{Class: consts.NETWORK_ERROR}, want: false},
+		{err: &price.FetchError{Class: consts.STORE_ERROR}, want: false},
+		{err: errors.New("timeout"), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isPermanentFailure(tt.err); got != tt.want {
+			t.Errorf("isPermanentFailure(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIntersectTickers(t *testing.T) {
+	queued := []string{"AAA", "bbb", "CCC"}
+
+	if got := intersectTickers(queued, nil); !reflect.DeepEqual(got, queued) {
+		t.Errorf("intersectTickers() without filter tickers = %v, want %v", got, queued)
+	}
+
+	if got := intersectTickers(queued, []string{"BBB", "ccc", "DDD"}); !reflect.DeepEqual(got, []string{"bbb", "CCC"}) {
+		t.Errorf("intersectTickers() = %v, want [bbb CCC]", got)
+	}
+
+	if got := intersectTickers(queued, []string{"DDD"}); len(got) != 0 {
+		t.Errorf("intersectTickers() of disjoint tickers = %v, want none", got)
+	}
+}
+
+func TestScrapeAssetPricesKeepsPermanentFailures(t *testing.T) {
+	notFound := &price.FetchError{Class: consts.NOT_FOUND_ERROR, Err: errors.New("no quote")}
+	timeout := &price.FetchError{Class: consts.NETWORK_ERROR, Err: errors.New("timeout")}
+
+	source := &scriptedSource{
+		errs: map[string][]error{
+			"DEAD": failEvery(notFound, 10),
+			"LIVE": {timeout},
+		},
+		fetches: map[string]int{},
+	}
+
+	// the dead ticker failed the runs before
+	store := newScraperStore("DEAD", "LIVE", "OK")
+	store.failures["DEAD"] = 2
+	store.delisted["DEAD"] = true
+	store.queued["LIVE"] = true
+
+	s := newTestPriceScraper(t, source, store, 3, 0)
+	s.scrapeAssetPrices(context.Background(), store.assets)
+
+	if source.fetches["DEAD"] != 1 || source.fetches["LIVE"] != 2 || source.fetches["OK"] != 1 {
+		t.Errorf("fetches = %v, want DEAD and OK once and LIVE retried once", source.fetches)
+	}
+
+	if store.failures["DEAD"] != 3 {
+		t.Errorf("consecutive failures of DEAD = %d, want 3", store.failures["DEAD"])
+	}
+
+	if !store.delisted["DEAD"] {
+		t.Errorf("DEAD not flagged as suspected delisted, want the flag kept")
+	}
+
+	if _, ok := store.failures["LIVE"]; ok {
+		t.Errorf("failure of LIVE = %d, want it cleared after the retry succeeded", store.failures["LIVE"])
+	}
+
+	if len(store.queued) != 0 {
+		t.Errorf("queued retries = %v, want none", store.queued)
+	}
+}
+
+func TestScrapeAssetPricesQuarantinesThroughRetries(t *testing.T) {
+	notFound := &price.FetchError{Class: consts.NOT_FOUND_ERROR, Err: errors.New("no quote")}
+	timeout := &price.FetchError{Class: consts.NETWORK_ERROR, Err: errors.New("timeout")}
+
+	store := newScraperStore("DEAD", "LIVE")
+
+	// every run retries the live ticker, the dead one still counts towards its quarantine
+	for run := 1; run <= 3; run++ {
+		source := &scriptedSource{
+			errs: map[string][]error{
+				"DEAD": failEvery(notFound, 10),
+				"LIVE": {timeout},
+			},
+			fetches: map[string]int{},
+		}
+
+		s := newTestPriceScraper(t, source, store, 3, 3)
+		s.scrapeAssetPrices(context.Background(), store.assets)
+
+		if store.failures["DEAD"] != int64(run) {
+			t.Fatalf("run %d: consecutive failures of DEAD = %d, want %d", run, store.failures["DEAD"], run)
+		}
+
+		if quarantined := store.quarantined["DEAD"]; quarantined != (run == 3) {
+			t.Fatalf("run %d: DEAD quarantined = %v, want %v", run, quarantined, run == 3)
+		}
+	}
+
+	if store.quarantined["LIVE"] || store.failures["LIVE"] != 0 {
+		t.Errorf("LIVE quarantined = %v with %d failures, want neither", store.quarantined["LIVE"], store.failures["LIVE"])
+	}
+}
+
+func TestScrapeAssetPricesFromCheckpointRetriesBeforeLease(t *testing.T) {
+	source := &scriptedSource{fetches: map[string]int{}}
+
+	store := newScraperStore("PPP", "QQQ", "RRR")
+	store.page = store.findAssets([]string{"PPP", "QQQ"})
+	store.queued["QQQ"] = true
+	store.queued["RRR"] = true
+
+	s := newTestPriceScraper(t, source, store, 3, 0)
+	s.ScrapeAssetPricesFromCheckpoint(10)
+
+	if fmt.Sprint(store.trace) != "[retries lease release]" {
+		t.Errorf("trace = %v, want the retry queue read before the page is leased", store.trace)
+	}
+
+	if !reflect.DeepEqual(source.fetches, map[string]int{"PPP": 1, "QQQ": 1, "RRR": 1}) {
+		t.Errorf("fetches = %v, want each ticker once", source.fetches)
+	}
+
+	if len(store.queued) != 0 {
+		t.Errorf("queued retries = %v, want none", store.queued)
+	}
+}
@@ -0,0 +1,158 @@
+package scraper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/symbols"
+)
+
+// snippetSize is the number of leading bytes of a response body hashed to recognize the same page
+const snippetSize = 1024
+
+// NewPriceSource creates the price source selected in the scraper config
+func NewPriceSource(conf *config.ScraperConfig, log logger.ContextLog) (price.PriceSource, error) {
+	switch conf.PriceSource {
+	case consts.YAHOO_HTML_SOURCE:
+		return NewYahooHTMLSource(log), nil
+	case consts.YAHOO_QUOTE_SOURCE, "":
+		return NewYahooQuoteSource(conf, log)
+	default:
+		return nil, fmt.Errorf("unknown price source %s", conf.PriceSource)
+	}
+}
+
+// normalizeMarketState maps the market states reported by yahoo to PRE, REGULAR, POST or CLOSED
+func normalizeMarketState(state string) string {
+	switch strings.ToUpper(state) {
+	case "PRE":
+		return consts.MARKET_STATE_PRE
+	case "REGULAR":
+		return consts.MARKET_STATE_REGULAR
+	case "POST":
+		return consts.MARKET_STATE_POST
+	// yahoo reports the hours between the extended sessions as PREPRE and POSTPOST
+	case "CLOSED", "PREPRE", "POSTPOST":
+		return consts.MARKET_STATE_CLOSED
+	default:
+		return ""
+	}
+}
+
+// assetQuoteType returns the quote type of an asset, the type yahoo reports is used for assets
+// without a known type
+func assetQuoteType(asset *entities.Asset, reported string) string {
+	if symbols.NormalizeQuoteType(asset.Type) == "" {
+		if quoteType := symbols.NormalizeQuoteType(reported); quoteType != "" {
+			return quoteType
+		}
+	}
+
+	return symbols.QuoteType(asset)
+}
+
+// minorUnits are the currencies yahoo quotes some exchanges in, in hundredths of the main
+// currency, keyed as yahoo reports them. Pence differ from pounds by case only
+var minorUnits = map[string]string{
+	"GBp": "GBP",
+	"GBX": "GBP",
+	"ZAc": "ZAR",
+	"ZAC": "ZAR",
+	"ILA": "ILS",
+}
+
+// currencyUnit returns the main currency of a currency yahoo reports and the factor converting
+// amounts in the reported currency to the main one, a hundredth for the minor units
+func currencyUnit(reported string) (string, float64) {
+	reported = strings.TrimSpace(reported)
+	if currency, ok := minorUnits[reported]; ok {
+		return currency, 0.01
+	}
+
+	return strings.ToUpper(reported), 1
+}
+
+// quoteCurrency returns the currency of a quote and the factor converting its amounts to that
+// currency, the asset currency when yahoo reports none. Indices are quoted in points and have no
+// currency
+func quoteCurrency(asset *entities.Asset, quoteType string, reported string) (string, float64) {
+	if quoteType == consts.INDEX_QUOTE_TYPE {
+		return "", 1
+	}
+
+	if currency, factor := currencyUnit(reported); currency != "" {
+		return currency, factor
+	}
+
+	return asset.Currency, 1
+}
+
+// toMainUnit converts the prices of a quote with the factor of its reported currency, see
+// currencyUnit. Volumes, percentages and the market cap yahoo reports in the main unit are kept
+func toMainUnit(assetPrice *entities.AssetPrice, factor float64) {
+	if factor == 1 {
+		return
+	}
+
+	prices := []*float64{
+		&assetPrice.Price,
+		&assetPrice.PreviousClose,
+		&assetPrice.Open,
+		&assetPrice.DayHigh,
+		&assetPrice.DayLow,
+		&assetPrice.FiftyTwoWeekHigh,
+		&assetPrice.FiftyTwoWeekLow,
+		&assetPrice.Bid,
+		&assetPrice.Ask,
+		&assetPrice.Change,
+		&assetPrice.PreMarketPrice,
+		&assetPrice.PreMarketChange,
+		&assetPrice.PostMarketPrice,
+		&assetPrice.PostMarketChange,
+	}
+
+	for _, price := range prices {
+		*price *= factor
+	}
+}
+
+// navDate returns the day (midnight UTC) of a mutual fund quote at marketTime (unix seconds), the
+// day is taken in the exchange timezone when it is known
+func navDate(marketTime int64, timezone string) int64 {
+	t := time.Unix(marketTime, 0).UTC()
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		t = t.In(loc)
+	}
+
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()
+}
+
+// snippetHash returns the hash of the beginning of a response body, empty when there is no body
+func snippetHash(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if len(body) > snippetSize {
+		body = body[:snippetSize]
+	}
+
+	sum := sha1.Sum(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// isAuthError reports whether the error is yahoo rejecting the cookie or the crumb
+func isAuthError(err error) bool {
+	var fetchErr *price.FetchError
+	return errors.As(err, &fetchErr) && fetchErr.Class == consts.AUTH_ERROR
+}
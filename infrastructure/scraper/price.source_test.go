@@ -0,0 +1,62 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+func TestCurrencyUnit(t *testing.T) {
+	tests := []struct {
+		reported     string
+		wantCurrency string
+		wantFactor   float64
+	}{
+		{reported: "GBp", wantCurrency: "GBP", wantFactor: 0.01},
+		{reported: "GBX", wantCurrency: "GBP", wantFactor: 0.01},
+		{reported: "GBP", wantCurrency: "GBP", wantFactor: 1},
+		{reported: "ZAc", wantCurrency: "ZAR", wantFactor: 0.01},
+		{reported: "ILA", wantCurrency: "ILS", wantFactor: 0.01},
+		{reported: "ILS", wantCurrency: "ILS", wantFactor: 1},
+		{reported: "cad", wantCurrency: "CAD", wantFactor: 1},
+		{reported: "", wantCurrency: "", wantFactor: 1},
+	}
+
+	for _, tt := range tests {
+		currency, factor := currencyUnit(tt.reported)
+		if currency != tt.wantCurrency || factor != tt.wantFactor {
+			t.Errorf("currencyUnit(%q) = %q, %v, want %q, %v", tt.reported, currency, factor, tt.wantCurrency, tt.wantFactor)
+		}
+	}
+}
+
+func TestQuoteCurrency(t *testing.T) {
+	asset := &entities.Asset{Ticker: "VOD", Currency: "GBP"}
+
+	if currency, factor := quoteCurrency(asset, consts.EQUITY_QUOTE_TYPE, "GBp"); currency != "GBP" || factor != 0.01 {
+		t.Errorf("quoteCurrency(GBp) = %q, %v, want GBP in pence", currency, factor)
+	}
+
+	if currency, factor := quoteCurrency(asset, consts.EQUITY_QUOTE_TYPE, ""); currency != "GBP" || factor != 1 {
+		t.Errorf("quoteCurrency() without currency = %q, %v, want the asset currency", currency, factor)
+	}
+
+	if currency, _ := quoteCurrency(asset, consts.INDEX_QUOTE_TYPE, "GBp"); currency != "" {
+		t.Errorf("quoteCurrency() of an index = %q, want none", currency)
+	}
+}
+
+func TestToMainUnit(t *testing.T) {
+	assetPrice := &entities.AssetPrice{Price: 7250, Bid: 7240, Change: -50, ChangePercent: -0.68, Volume: 1000, MarketCap: 19000000000}
+
+	toMainUnit(assetPrice, 0.01)
+
+	if assetPrice.Price != 72.5 || assetPrice.Bid != 72.4 || assetPrice.Change != -0.5 {
+		t.Errorf("prices = %v bid %v change %v, want 72.5 bid 72.4 change -0.5", assetPrice.Price, assetPrice.Bid, assetPrice.Change)
+	}
+
+	if assetPrice.ChangePercent != -0.68 || assetPrice.Volume != 1000 || assetPrice.MarketCap != 19000000000 {
+		t.Errorf("percent %v volume %v market cap %v, want them kept", assetPrice.ChangePercent, assetPrice.Volume, assetPrice.MarketCap)
+	}
+}
@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/corpactions"
+)
+
+// SplitAdjuster struct
+type SplitAdjuster struct {
+	corpActionService *corpactions.Service
+	assetService      *assets.Service
+	log               logger.ContextLog
+	errorTickers      []string
+}
+
+// NewSplitAdjuster create new split adjuster
+func NewSplitAdjuster(assetService *assets.Service, corpActionService *corpactions.Service, log logger.ContextLog) *SplitAdjuster {
+	return &SplitAdjuster{
+		assetService:      assetService,
+		corpActionService: corpActionService,
+		log:               log,
+	}
+}
+
+// AdjustSplits detects splits in the price history since from of the given tickers, or of all
+// assets when no ticker is given, then applies every pending split to their price history
+func (s *SplitAdjuster) AdjustSplits(from time.Time, tickers []string) {
+	ctx := context.Background()
+
+	var assets []*entities.Asset
+	var err error
+	if len(tickers) > 0 {
+		assets, err = s.assetService.GetAssetsByTickers(ctx, tickers)
+	} else {
+		assets, err = s.assetService.GetAllAssets(ctx, nil)
+	}
+
+	if err != nil {
+		s.log.Error(ctx, "get assets list failed", "error", err)
+		return
+	}
+
+	for _, asset := range assets {
+		s.log.Info(ctx, "adjusting splits", "ticker", asset.Ticker, "from", from)
+
+		if _, err := s.corpActionService.DetectSplits(ctx, asset.Ticker, from, time.Now().UTC()); err != nil {
+			s.errorTickers = append(s.errorTickers, asset.Ticker)
+			continue
+		}
+
+		if _, err := s.corpActionService.ApplySplits(ctx, asset.Ticker); err != nil {
+			s.errorTickers = append(s.errorTickers, asset.Ticker)
+		}
+	}
+}
+
+// Close adjuster
+func (s *SplitAdjuster) Close() {
+	s.log.Info(context.Background(), "DONE - ADJUSTING SPLITS", "errorTickers", s.errorTickers)
+}
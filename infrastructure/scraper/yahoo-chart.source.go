@@ -0,0 +1,237 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/symbols"
+)
+
+// secondsPerDay number of seconds in a day
+const secondsPerDay = 24 * 60 * 60
+
+// YahooChartSource struct
+type YahooChartSource struct {
+	*yahooClient
+	log logger.ContextLog
+}
+
+// chartResponse is the payload returned by the chart endpoint
+type chartResponse struct {
+	Chart struct {
+		Result []*yahooChart `json:"result"`
+		Error  *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// yahooChart is the chart of a single symbol
+type yahooChart struct {
+	Meta struct {
+		Symbol    string `json:"symbol"`
+		Currency  string `json:"currency"`
+		GMTOffset int64  `json:"gmtoffset"`
+	} `json:"meta"`
+	Timestamp []int64 `json:"timestamp"`
+	Events    struct {
+		Dividends map[string]*yahooDividend `json:"dividends"`
+		Splits    map[string]*yahooSplit    `json:"splits"`
+	} `json:"events"`
+	Indicators struct {
+		Quote []struct {
+			Open   []*float64 `json:"open"`
+			High   []*float64 `json:"high"`
+			Low    []*float64 `json:"low"`
+			Close  []*float64 `json:"close"`
+			Volume []*int64   `json:"volume"`
+		} `json:"quote"`
+		AdjClose []struct {
+			AdjClose []*float64 `json:"adjclose"`
+		} `json:"adjclose"`
+	} `json:"indicators"`
+}
+
+// yahooDividend is a dividend event of the chart
+type yahooDividend struct {
+	Date   int64   `json:"date"`
+	Amount float64 `json:"amount"`
+}
+
+// yahooSplit is a split event of the chart
+type yahooSplit struct {
+	Date        int64   `json:"date"`
+	Numerator   float64 `json:"numerator"`
+	Denominator float64 `json:"denominator"`
+	SplitRatio  string  `json:"splitRatio"`
+}
+
+// NewYahooChartSource create new yahoo chart source
+func NewYahooChartSource(conf *config.ScraperConfig, log logger.ContextLog) (*YahooChartSource, error) {
+	client, err := newYahooClient(conf, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &YahooChartSource{
+		yahooClient: client,
+		log:         log,
+	}, nil
+}
+
+// Name returns the source name
+func (s *YahooChartSource) Name() string {
+	return consts.YAHOO_CHART_SOURCE
+}
+
+// FetchDailyBars fetches the daily bars of an asset between from and to (unix seconds)
+func (s *YahooChartSource) FetchDailyBars(ctx context.Context, asset *entities.Asset, from int64, to int64) ([]*entities.PriceBar, error) {
+	chart, err := s.fetchChart(ctx, asset, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chart.Indicators.Quote) == 0 {
+		return nil, nil
+	}
+
+	quote := chart.Indicators.Quote[0]
+
+	var adjCloses []*float64
+	if len(chart.Indicators.AdjClose) > 0 {
+		adjCloses = chart.Indicators.AdjClose[0].AdjClose
+	}
+
+	currency, factor := quoteCurrency(asset, symbols.QuoteType(asset), chart.Meta.Currency)
+
+	// the chart adjusts the bars for the splits known when they are fetched
+	fetchedAt := time.Now().UTC().Unix()
+
+	var bars []*entities.PriceBar
+	for i, ts := range chart.Timestamp {
+		closePrice := floatAt(quote.Close, i)
+		// yahoo returns empty rows for days without trades
+		if closePrice == 0 {
+			continue
+		}
+
+		bars = append(bars, &entities.PriceBar{
+			Ticker:    asset.Ticker,
+			Date:      tradingDate(ts, chart.Meta.GMTOffset),
+			Currency:  currency,
+			Open:      floatAt(quote.Open, i) * factor,
+			High:      floatAt(quote.High, i) * factor,
+			Low:       floatAt(quote.Low, i) * factor,
+			Close:     closePrice * factor,
+			AdjClose:  floatAt(adjCloses, i) * factor,
+			Volume:    intAt(quote.Volume, i),
+			Source:    s.Name(),
+			FetchedAt: fetchedAt,
+		})
+	}
+
+	return bars, nil
+}
+
+// FetchEvents fetches the dividend and split events of an asset between from and to (unix seconds)
+func (s *YahooChartSource) FetchEvents(ctx context.Context, asset *entities.Asset, from int64, to int64) ([]*entities.AssetEvent, error) {
+	chart, err := s.fetchChart(ctx, asset, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, factor := quoteCurrency(asset, symbols.QuoteType(asset), chart.Meta.Currency)
+
+	var events []*entities.AssetEvent
+	for _, dividend := range chart.Events.Dividends {
+		events = append(events, &entities.AssetEvent{
+			Ticker:   asset.Ticker,
+			Type:     consts.DIVIDEND_EVENT,
+			Date:     tradingDate(dividend.Date, chart.Meta.GMTOffset),
+			Amount:   dividend.Amount * factor,
+			Currency: currency,
+			Source:   s.Name(),
+		})
+	}
+
+	for _, split := range chart.Events.Splits {
+		events = append(events, &entities.AssetEvent{
+			Ticker:      asset.Ticker,
+			Type:        consts.SPLIT_EVENT,
+			Date:        tradingDate(split.Date, chart.Meta.GMTOffset),
+			Numerator:   split.Numerator,
+			Denominator: split.Denominator,
+			SplitRatio:  split.SplitRatio,
+			Source:      s.Name(),
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Date < events[j].Date
+	})
+
+	return events, nil
+}
+
+// fetchChart calls the chart endpoint for a single asset
+func (s *YahooChartSource) fetchChart(ctx context.Context, asset *entities.Asset, from int64, to int64) (*yahooChart, error) {
+	symbol := symbols.YahooSymbol(asset)
+
+	s.log.Info(ctx, "fetching chart", "ticker", asset.Ticker, "symbol", symbol, "from", from, "to", to)
+	body, status, err := s.get(ctx, config.GetChartURL(s.queryURL, symbol, from, to))
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", status)
+	}
+
+	var resp chartResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Chart.Error != nil {
+		return nil, fmt.Errorf("chart response error %s: %s", resp.Chart.Error.Code, resp.Chart.Error.Description)
+	}
+
+	if len(resp.Chart.Result) == 0 {
+		return nil, fmt.Errorf("symbol not found in chart response")
+	}
+
+	return resp.Chart.Result[0], nil
+}
+
+// tradingDate returns midnight UTC of the exchange's local day the timestamp falls on
+func tradingDate(ts int64, gmtOffset int64) int64 {
+	local := ts + gmtOffset
+	return local - local%secondsPerDay
+}
+
+// floatAt returns the value at index i or zero when it is missing
+func floatAt(vals []*float64, i int) float64 {
+	if i >= len(vals) || vals[i] == nil {
+		return 0
+	}
+
+	return *vals[i]
+}
+
+// intAt returns the value at index i or zero when it is missing
+func intAt(vals []*int64, i int) int64 {
+	if i >= len(vals) || vals[i] == nil {
+		return 0
+	}
+
+	return *vals[i]
+}
@@ -0,0 +1,43 @@
+package scraper
+
+import "testing"
+
+func TestTradingDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		ts        int64
+		gmtOffset int64
+		want      int64
+	}{
+		// 2023-09-20 20:00 UTC
+		{name: "utc", ts: 1695240000, gmtOffset: 0, want: 1695168000},
+		{name: "behind utc same day", ts: 1695240000, gmtOffset: -4 * 3600, want: 1695168000},
+		{name: "ahead of utc next day", ts: 1695240000, gmtOffset: 9 * 3600, want: 1695254400},
+		// 2023-09-21 02:00 UTC is still the 20th in New York
+		{name: "behind utc previous day", ts: 1695261600, gmtOffset: -4 * 3600, want: 1695168000},
+		{name: "midnight", ts: 1695168000, gmtOffset: 0, want: 1695168000},
+	}
+
+	for _, tt := range tests {
+		if got := tradingDate(tt.ts, tt.gmtOffset); got != tt.want {
+			t.Errorf("%s: tradingDate(%d, %d) = %d, want %d", tt.name, tt.ts, tt.gmtOffset, got, tt.want)
+		}
+	}
+}
+
+func TestFloatAt(t *testing.T) {
+	one := 1.5
+	vals := []*float64{&one, nil}
+
+	if got := floatAt(vals, 0); got != 1.5 {
+		t.Errorf("floatAt(0) = %v, want 1.5", got)
+	}
+
+	if got := floatAt(vals, 1); got != 0 {
+		t.Errorf("floatAt(nil) = %v, want 0", got)
+	}
+
+	if got := floatAt(vals, 2); got != 0 {
+		t.Errorf("floatAt(out of range) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,546 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// Extractor names
+const (
+	finStreamerExtractor = "fin-streamer"
+	rootAppMainExtractor = "root-app-main"
+	reactIDSpanExtractor = "reactid-span"
+)
+
+// rootAppMainRegex matches the json blob assigned to root.App.main in the page scripts
+var rootAppMainRegex = regexp.MustCompile(`(?s)root\.App\.main\s*=\s*(\{.*?\});\s*\n`)
+
+// currencyRegex matches the "Currency in USD" note of the quote header
+var currencyRegex = regexp.MustCompile(`Currency in ([A-Za-z]{3})\b`)
+
+// navDateRegex matches the date of the "As of September 20 04:00PM EDT" note of mutual fund quote headers
+var navDateRegex = regexp.MustCompile(`(?:As of|At close:?)\s+([A-Z][a-z]+ \d{1,2})(?:,\s*(\d{4}))?`)
+
+// priceExtractor extracts an asset price from a yahoo quote page
+type priceExtractor struct {
+	name    string
+	extract func(doc *goquery.Selection, symbol string, assetPrice *entities.AssetPrice) error
+}
+
+// priceExtractors are tried in order until one of them finds the price
+var priceExtractors = []priceExtractor{
+	{name: finStreamerExtractor, extract: extractFinStreamer},
+	{name: rootAppMainExtractor, extract: extractRootAppMain},
+	{name: reactIDSpanExtractor, extract: extractReactIDSpan},
+}
+
+// quoteTypeDetails fill the quote fields that the page layout of a quote type shows its own way
+var quoteTypeDetails = map[string]func(doc *goquery.Selection, symbol string, assetPrice *entities.AssetPrice){
+	consts.MUTUAL_FUND_QUOTE_TYPE: fillNavDetails,
+	consts.CRYPTO_QUOTE_TYPE:      fillCryptoDetails,
+}
+
+// extractPrice runs the extractors in order and returns the name of the one that found the price.
+// The symbol is the yahoo symbol of the quote page
+func extractPrice(doc *goquery.Selection, symbol string, assetPrice *entities.AssetPrice) (string, error) {
+	var errs []string
+
+	for _, extractor := range priceExtractors {
+		if err := extractor.extract(doc, symbol, assetPrice); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", extractor.name, err))
+			continue
+		}
+
+		return extractor.name, nil
+	}
+
+	return "", fmt.Errorf("price not found (%s)", strings.Join(errs, "; "))
+}
+
+///////////////////////////////////////////////////////////
+// fin-streamer
+///////////////////////////////////////////////////////////
+
+// extractFinStreamer reads the price from the fin-streamer element of the quoted symbol
+func extractFinStreamer(doc *goquery.Selection, symbol string, assetPrice *entities.AssetPrice) error {
+	val, ok := finStreamerValue(doc, symbol, "regularMarketPrice")
+	if !ok {
+		return fmt.Errorf("element not found")
+	}
+
+	price, err := parseNumber(val)
+	if err != nil {
+		return err
+	}
+
+	if price <= 0 {
+		return fmt.Errorf("invalid price %s", val)
+	}
+
+	assetPrice.Price = price
+	return nil
+}
+
+// finStreamerValue finds the value of a fin-streamer field. The page streams other symbols
+// too (indices in the header), so elements of the quoted symbol are preferred
+func finStreamerValue(doc *goquery.Selection, symbol string, field string) (string, bool) {
+	sel := doc.Find(fmt.Sprintf("fin-streamer[data-field=%s]", field))
+
+	var val string
+	found := false
+	sel.EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !strings.EqualFold(s.AttrOr("data-symbol", ""), symbol) {
+			return true
+		}
+
+		val, found = finStreamerText(s)
+		return !found
+	})
+
+	if !found {
+		val, found = finStreamerText(doc.Find("div[id=quote-header-info]").Find(fmt.Sprintf("fin-streamer[data-field=%s]", field)).First())
+	}
+
+	return val, found
+}
+
+// finStreamerText returns the value attribute of a fin-streamer or its text
+func finStreamerText(s *goquery.Selection) (string, bool) {
+	if s.Length() == 0 {
+		return "", false
+	}
+
+	if val, ok := s.Attr("value"); ok && val != "" {
+		return val, true
+	}
+
+	val := strings.TrimSpace(s.Text())
+	return val, val != ""
+}
+
+///////////////////////////////////////////////////////////
+// root.App.main
+///////////////////////////////////////////////////////////
+
+// yahooRawValue is a formatted number of the root.App.main stores
+type yahooRawValue struct {
+	Raw float64 `json:"raw"`
+}
+
+// yahooSummaryPrice is the price module of the quote summary store
+type yahooSummaryPrice struct {
+	Symbol                     string        `json:"symbol"`
+	Currency                   string        `json:"currency"`
+	Exchange                   string        `json:"exchange"`
+	RegularMarketPrice         yahooRawValue `json:"regularMarketPrice"`
+	RegularMarketTime          int64         `json:"regularMarketTime"`
+	MarketState                string        `json:"marketState"`
+	PreMarketPrice             yahooRawValue `json:"preMarketPrice"`
+	PreMarketChange            yahooRawValue `json:"preMarketChange"`
+	PreMarketChangePercent     yahooRawValue `json:"preMarketChangePercent"`
+	PreMarketTime              int64         `json:"preMarketTime"`
+	PostMarketPrice            yahooRawValue `json:"postMarketPrice"`
+	PostMarketChange           yahooRawValue `json:"postMarketChange"`
+	PostMarketChangePercent    yahooRawValue `json:"postMarketChangePercent"`
+	PostMarketTime             int64         `json:"postMarketTime"`
+	RegularMarketPreviousClose yahooRawValue `json:"regularMarketPreviousClose"`
+	RegularMarketOpen          yahooRawValue `json:"regularMarketOpen"`
+	RegularMarketDayHigh       yahooRawValue `json:"regularMarketDayHigh"`
+	RegularMarketDayLow        yahooRawValue `json:"regularMarketDayLow"`
+	RegularMarketVolume        yahooRawValue `json:"regularMarketVolume"`
+	AverageDailyVolume3Month   yahooRawValue `json:"averageDailyVolume3Month"`
+	MarketCap                  yahooRawValue `json:"marketCap"`
+	RegularMarketChange        yahooRawValue `json:"regularMarketChange"`
+	RegularMarketChangePercent yahooRawValue `json:"regularMarketChangePercent"`
+}
+
+// yahooSummaryDetail is the summary detail module of the quote summary store
+type yahooSummaryDetail struct {
+	Bid              yahooRawValue `json:"bid"`
+	Ask              yahooRawValue `json:"ask"`
+	FiftyTwoWeekHigh yahooRawValue `json:"fiftyTwoWeekHigh"`
+	FiftyTwoWeekLow  yahooRawValue `json:"fiftyTwoWeekLow"`
+	AverageVolume    yahooRawValue `json:"averageVolume"`
+}
+
+// yahooSummaryQuoteType is the quote type module of the quote summary store
+type yahooSummaryQuoteType struct {
+	QuoteType        string `json:"quoteType"`
+	TimeZoneFullName string `json:"timeZoneFullName"`
+}
+
+// rootAppMain is the part of the root.App.main blob we read
+type rootAppMain struct {
+	Context struct {
+		Dispatcher struct {
+			Stores struct {
+				QuoteSummaryStore struct {
+					Price         *yahooSummaryPrice     `json:"price"`
+					SummaryDetail *yahooSummaryDetail    `json:"summaryDetail"`
+					QuoteType     *yahooSummaryQuoteType `json:"quoteType"`
+				} `json:"QuoteSummaryStore"`
+			} `json:"stores"`
+		} `json:"dispatcher"`
+	} `json:"context"`
+}
+
+// extractRootAppMain reads the price from the json embedded in the root.App.main script
+func extractRootAppMain(doc *goquery.Selection, _ string, assetPrice *entities.AssetPrice) error {
+	appMain, err := parseRootAppMain(doc)
+	if err != nil {
+		return err
+	}
+
+	summaryPrice := appMain.Context.Dispatcher.Stores.QuoteSummaryStore.Price
+	if summaryPrice == nil || summaryPrice.RegularMarketPrice.Raw == 0 {
+		return fmt.Errorf("price not in quote summary store")
+	}
+
+	assetPrice.Price = summaryPrice.RegularMarketPrice.Raw
+	assetPrice.Exchange = summaryPrice.Exchange
+	assetPrice.MarketTime = summaryPrice.RegularMarketTime
+	return nil
+}
+
+// parseRootAppMain finds and decodes the root.App.main script
+func parseRootAppMain(doc *goquery.Selection) (*rootAppMain, error) {
+	var blob string
+	doc.Find("script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		match := rootAppMainRegex.FindStringSubmatch(s.Text())
+		if match == nil {
+			return true
+		}
+
+		blob = match[1]
+		return false
+	})
+
+	if blob == "" {
+		return nil, fmt.Errorf("script not found")
+	}
+
+	var appMain rootAppMain
+	if err := json.Unmarshal([]byte(blob), &appMain); err != nil {
+		return nil, err
+	}
+
+	return &appMain, nil
+}
+
+///////////////////////////////////////////////////////////
+// legacy reactid span
+///////////////////////////////////////////////////////////
+
+// extractReactIDSpan reads the price from the span the old react layout rendered it in
+func extractReactIDSpan(doc *goquery.Selection, _ string, assetPrice *entities.AssetPrice) error {
+	span := doc.Find("div[id=quote-header-info]").Find("span[data-reactid='31']").First()
+	if span.Length() == 0 {
+		return fmt.Errorf("element not found")
+	}
+
+	price, err := parseNumber(span.Text())
+	if err != nil {
+		return err
+	}
+
+	if price <= 0 {
+		return fmt.Errorf("invalid price %s", span.Text())
+	}
+
+	assetPrice.Price = price
+	return nil
+}
+
+///////////////////////////////////////////////////////////
+// quote details
+///////////////////////////////////////////////////////////
+
+// fillQuoteDetails fills the quote fields other than the price. Values of the root.App.main
+// stores are used first, the rendered summary table fills whatever they did not have, then
+// the fields particular to the layout of the quote type
+func fillQuoteDetails(doc *goquery.Selection, symbol string, quoteType string, assetPrice *entities.AssetPrice) {
+	if appMain, err := parseRootAppMain(doc); err == nil {
+		fillFromRootAppMain(appMain, assetPrice)
+	}
+
+	fillFromSummaryTable(doc, symbol, assetPrice)
+
+	if details, ok := quoteTypeDetails[quoteType]; ok {
+		details(doc, symbol, assetPrice)
+	}
+}
+
+// extractCurrency returns the currency the quote page shows as it shows it, see currencyUnit, from
+// the quote summary store or else from the note of the quote header. Empty when the page shows none
+func extractCurrency(doc *goquery.Selection) string {
+	if appMain, err := parseRootAppMain(doc); err == nil {
+		if p := appMain.Context.Dispatcher.Stores.QuoteSummaryStore.Price; p != nil && p.Currency != "" {
+			return strings.TrimSpace(p.Currency)
+		}
+	}
+
+	for _, sel := range []*goquery.Selection{doc.Find("div[id=quote-header-info]"), doc.Find("body")} {
+		if match := currencyRegex.FindStringSubmatch(sel.Text()); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
+
+// fillFromRootAppMain fills quote fields from the quote summary store
+func fillFromRootAppMain(appMain *rootAppMain, assetPrice *entities.AssetPrice) {
+	store := appMain.Context.Dispatcher.Stores.QuoteSummaryStore
+
+	if p := store.Price; p != nil {
+		setInt(&assetPrice.MarketTime, p.RegularMarketTime)
+		setFloat(&assetPrice.PreviousClose, p.RegularMarketPreviousClose.Raw)
+		setFloat(&assetPrice.Open, p.RegularMarketOpen.Raw)
+		setFloat(&assetPrice.DayHigh, p.RegularMarketDayHigh.Raw)
+		setFloat(&assetPrice.DayLow, p.RegularMarketDayLow.Raw)
+		setInt(&assetPrice.Volume, int64(p.RegularMarketVolume.Raw))
+		setInt(&assetPrice.AverageVolume, int64(p.AverageDailyVolume3Month.Raw))
+		setInt(&assetPrice.MarketCap, int64(p.MarketCap.Raw))
+		setFloat(&assetPrice.Change, p.RegularMarketChange.Raw)
+		// the price module keeps the change percent as a fraction
+		setFloat(&assetPrice.ChangePercent, p.RegularMarketChangePercent.Raw*100)
+		setString(&assetPrice.MarketState, normalizeMarketState(p.MarketState))
+		setFloat(&assetPrice.PreMarketPrice, p.PreMarketPrice.Raw)
+		setFloat(&assetPrice.PreMarketChange, p.PreMarketChange.Raw)
+		setFloat(&assetPrice.PreMarketChangePercent, p.PreMarketChangePercent.Raw*100)
+		setInt(&assetPrice.PreMarketTime, p.PreMarketTime)
+		setFloat(&assetPrice.PostMarketPrice, p.PostMarketPrice.Raw)
+		setFloat(&assetPrice.PostMarketChange, p.PostMarketChange.Raw)
+		setFloat(&assetPrice.PostMarketChangePercent, p.PostMarketChangePercent.Raw*100)
+		setInt(&assetPrice.PostMarketTime, p.PostMarketTime)
+	}
+
+	if q := store.QuoteType; q != nil {
+		setString(&assetPrice.ExchangeTimezone, q.TimeZoneFullName)
+	}
+
+	if d := store.SummaryDetail; d != nil {
+		setFloat(&assetPrice.Bid, d.Bid.Raw)
+		setFloat(&assetPrice.Ask, d.Ask.Raw)
+		setFloat(&assetPrice.FiftyTwoWeekHigh, d.FiftyTwoWeekHigh.Raw)
+		setFloat(&assetPrice.FiftyTwoWeekLow, d.FiftyTwoWeekLow.Raw)
+		setInt(&assetPrice.AverageVolume, int64(d.AverageVolume.Raw))
+	}
+}
+
+// fillFromSummaryTable fills quote fields from the rendered summary table and header
+func fillFromSummaryTable(doc *goquery.Selection, symbol string, assetPrice *entities.AssetPrice) {
+	cell := func(name string) string {
+		return strings.TrimSpace(doc.Find(fmt.Sprintf("td[data-test=%s-value]", name)).First().Text())
+	}
+
+	if v, err := parseNumber(cell("PREV_CLOSE")); err == nil {
+		setFloat(&assetPrice.PreviousClose, v)
+	}
+
+	if v, err := parseNumber(cell("OPEN")); err == nil {
+		setFloat(&assetPrice.Open, v)
+	}
+
+	// bid and ask are displayed as "price x size"
+	if v, err := parseNumber(strings.Split(cell("BID"), "x")[0]); err == nil {
+		setFloat(&assetPrice.Bid, v)
+	}
+
+	if v, err := parseNumber(strings.Split(cell("ASK"), "x")[0]); err == nil {
+		setFloat(&assetPrice.Ask, v)
+	}
+
+	if low, high, err := parseRange(cell("DAYS_RANGE")); err == nil {
+		setFloat(&assetPrice.DayLow, low)
+		setFloat(&assetPrice.DayHigh, high)
+	}
+
+	if low, high, err := parseRange(cell("FIFTY_TWO_WK_RANGE")); err == nil {
+		setFloat(&assetPrice.FiftyTwoWeekLow, low)
+		setFloat(&assetPrice.FiftyTwoWeekHigh, high)
+	}
+
+	if v, err := parseNumber(cell("TD_VOLUME")); err == nil {
+		setInt(&assetPrice.Volume, int64(v))
+	}
+
+	if v, err := parseNumber(cell("AVERAGE_VOLUME_3MONTH")); err == nil {
+		setInt(&assetPrice.AverageVolume, int64(v))
+	}
+
+	if v, err := parseAbbreviatedNumber(cell("MARKET_CAP")); err == nil {
+		setInt(&assetPrice.MarketCap, int64(v))
+	}
+
+	if val, ok := finStreamerValue(doc, symbol, "regularMarketChange"); ok {
+		if v, err := parseNumber(val); err == nil {
+			setFloat(&assetPrice.Change, v)
+		}
+	}
+
+	if val, ok := finStreamerValue(doc, symbol, "regularMarketChangePercent"); ok {
+		if v, err := parsePercent(val); err == nil {
+			setFloat(&assetPrice.ChangePercent, v)
+		}
+	}
+
+	if val, ok := finStreamerValue(doc, symbol, "preMarketPrice"); ok {
+		if v, err := parseNumber(val); err == nil {
+			setFloat(&assetPrice.PreMarketPrice, v)
+		}
+	}
+
+	if val, ok := finStreamerValue(doc, symbol, "postMarketPrice"); ok {
+		if v, err := parseNumber(val); err == nil {
+			setFloat(&assetPrice.PostMarketPrice, v)
+		}
+	}
+}
+
+// fillNavDetails fills the nav date of a mutual fund from its market time, or from the date of
+// the quote header note when the page has no market time
+func fillNavDetails(doc *goquery.Selection, symbol string, assetPrice *entities.AssetPrice) {
+	if val, ok := finStreamerValue(doc, symbol, "regularMarketTime"); ok {
+		if v, err := strconv.ParseInt(val, 10, 64); err == nil {
+			setInt(&assetPrice.MarketTime, v)
+		}
+	}
+
+	if assetPrice.MarketTime > 0 {
+		assetPrice.NavDate = navDate(assetPrice.MarketTime, assetPrice.ExchangeTimezone)
+		return
+	}
+
+	if date, err := parseHeaderNavDate(doc.Find("div[id=quote-header-info]").Text(), time.Now().UTC()); err == nil {
+		assetPrice.NavDate = date
+	}
+}
+
+// fillCryptoDetails fills the volume of a cryptocurrency, its summary table shows the 24 hour volume
+func fillCryptoDetails(doc *goquery.Selection, _ string, assetPrice *entities.AssetPrice) {
+	cell := strings.TrimSpace(doc.Find("td[data-test=VOLUME_24HR-value]").First().Text())
+	if v, err := parseAbbreviatedNumber(cell); err == nil {
+		setInt(&assetPrice.Volume, int64(v))
+	}
+}
+
+///////////////////////////////////////////////////////////
+// helpers
+///////////////////////////////////////////////////////////
+
+// setFloat sets a float field unless it already has a value
+func setFloat(field *float64, val float64) {
+	if *field == 0 {
+		*field = val
+	}
+}
+
+// setString sets a string field unless it already has a value
+func setString(field *string, val string) {
+	if *field == "" {
+		*field = val
+	}
+}
+
+// setInt sets an int field unless it already has a value
+func setInt(field *int64, val int64) {
+	if *field == 0 {
+		*field = val
+	}
+}
+
+// parseHeaderNavDate parses the date of the quote header note to a day (midnight UTC). The note
+// omits the year of recent dates, they are the latest such date not after now
+func parseHeaderNavDate(txt string, now time.Time) (int64, error) {
+	match := navDateRegex.FindStringSubmatch(txt)
+	if match == nil {
+		return 0, fmt.Errorf("nav date not found")
+	}
+
+	day, err := time.Parse("January 2", match[1])
+	if err != nil {
+		return 0, err
+	}
+
+	year := now.Year()
+	if match[2] != "" {
+		if year, err = strconv.Atoi(match[2]); err != nil {
+			return 0, err
+		}
+	}
+
+	date := time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	if match[2] == "" && date.After(now) {
+		date = date.AddDate(-1, 0, 0)
+	}
+
+	return date.Unix(), nil
+}
+
+// parseRange parses a displayed range such as "148.00 - 151.25"
+func parseRange(txt string) (float64, float64, error) {
+	parts := strings.Split(txt, " - ")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %s", txt)
+	}
+
+	low, err := parseNumber(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	high, err := parseNumber(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return low, high, nil
+}
+
+// parsePercent parses a displayed percentage such as "(+1.23%)"
+func parsePercent(txt string) (float64, error) {
+	return parseNumber(strings.Trim(strings.TrimSpace(txt), "()+%"))
+}
+
+// parseAbbreviatedNumber parses a displayed number with a magnitude suffix such as "2.5T"
+func parseAbbreviatedNumber(txt string) (float64, error) {
+	multipliers := map[string]float64{
+		"K": 1e3,
+		"M": 1e6,
+		"B": 1e9,
+		"T": 1e12,
+	}
+
+	txt = strings.TrimSpace(txt)
+	if txt == "" {
+		return 0, fmt.Errorf("empty value")
+	}
+
+	suffix := strings.ToUpper(txt[len(txt)-1:])
+	if multiplier, ok := multipliers[suffix]; ok {
+		val, err := parseNumber(txt[:len(txt)-1])
+		if err != nil {
+			return 0, err
+		}
+
+		return val * multiplier, nil
+	}
+
+	return parseNumber(txt)
+}
+
+// parseNumber parses a displayed number such as "1,234.56"
+func parseNumber(txt string) (float64, error) {
+	p := strings.TrimSpace(strings.Replace(txt, ",", "", -1))
+
+	return strconv.ParseFloat(p, 64)
+}
@@ -0,0 +1,245 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// loadQuotePage parses a saved yahoo quote page of testdata
+func loadQuotePage(t *testing.T, name string) *goquery.Selection {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+
+	return doc.Selection
+}
+
+func TestExtractQuotePages(t *testing.T) {
+	tests := []struct {
+		name          string
+		page          string
+		symbol        string
+		wantExtractor string
+		wantCurrency  string
+		want          entities.AssetPrice
+	}{
+		{
+			// the header streams indices first, the quoted symbol must win, and the stale
+			// root.App.main price is not used while its market fields fill the gaps
+			name:          "fin-streamer",
+			page:          "quote-fin-streamer.html",
+			symbol:        "AAPL",
+			wantExtractor: finStreamerExtractor,
+			wantCurrency:  "USD",
+			want: entities.AssetPrice{
+				Price:            175.49,
+				MarketTime:       1695240000,
+				MarketState:      consts.MARKET_STATE_REGULAR,
+				ExchangeTimezone: "America/New_York",
+				PreviousClose:    177.15,
+				Open:             176.48,
+				DayHigh:          176.51,
+				DayLow:           174.82,
+				FiftyTwoWeekHigh: 198.23,
+				FiftyTwoWeekLow:  124.17,
+				Volume:           56725385,
+				AverageVolume:    58932173,
+				MarketCap:        2500000000000,
+				Bid:              175.40,
+				Ask:              175.55,
+				Change:           -1.66,
+				ChangePercent:    -0.94,
+				PostMarketPrice:  175.60,
+			},
+		},
+		{
+			// the store values win over the summary table, which only fills what they lack
+			name:          "root.App.main",
+			page:          "quote-root-app-main.html",
+			symbol:        "SHOP.TO",
+			wantExtractor: rootAppMainExtractor,
+			wantCurrency:  "CAD",
+			want: entities.AssetPrice{
+				Price:            101.5,
+				Exchange:         "TOR",
+				MarketTime:       1695240000,
+				MarketState:      consts.MARKET_STATE_CLOSED,
+				ExchangeTimezone: "America/Toronto",
+				PreviousClose:    100.25,
+				Open:             100.75,
+				DayHigh:          102,
+				DayLow:           99.5,
+				FiftyTwoWeekHigh: 110,
+				FiftyTwoWeekLow:  60,
+				Volume:           1234567,
+				AverageVolume:    2000000,
+				MarketCap:        130000000000,
+				Bid:              101.40,
+				Change:           1.25,
+				ChangePercent:    3.125,
+			},
+		},
+		{
+			name:          "reactid span",
+			page:          "quote-reactid-span.html",
+			symbol:        "XIU.TO",
+			wantExtractor: reactIDSpanExtractor,
+			wantCurrency:  "CAD",
+			want: entities.AssetPrice{
+				Price:         31.42,
+				PreviousClose: 31.30,
+				Open:          31.35,
+				DayHigh:       31.50,
+				DayLow:        31.28,
+				Volume:        2345678,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := loadQuotePage(t, tt.page)
+
+			var got entities.AssetPrice
+			extractor, err := extractPrice(doc, tt.symbol, &got)
+			if err != nil {
+				t.Fatalf("extractPrice() error = %v", err)
+			}
+
+			if extractor != tt.wantExtractor {
+				t.Errorf("extractPrice() extractor = %q, want %q", extractor, tt.wantExtractor)
+			}
+
+			fillQuoteDetails(doc, tt.symbol, consts.EQUITY_QUOTE_TYPE, &got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("quote = %+v\nwant %+v", got, tt.want)
+			}
+
+			if currency := extractCurrency(doc); currency != tt.wantCurrency {
+				t.Errorf("extractCurrency() = %q, want %q", currency, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestExtractPriceMissing(t *testing.T) {
+	doc := loadQuotePage(t, "quote-missing-price.html")
+
+	var got entities.AssetPrice
+	extractor, err := extractPrice(doc, "ZZZZ", &got)
+	if err == nil {
+		t.Fatalf("extractPrice() = %q, want an error", extractor)
+	}
+
+	// every extractor is tried, in order
+	msg := err.Error()
+	last := -1
+	for _, name := range []string{finStreamerExtractor, rootAppMainExtractor, reactIDSpanExtractor} {
+		i := strings.Index(msg, name+":")
+		if i <= last {
+			t.Fatalf("extractPrice() error = %q, want %s tried after the previous extractors", msg, name)
+		}
+		last = i
+	}
+
+	if got.Price != 0 {
+		t.Errorf("Price = %v, want none", got.Price)
+	}
+}
+
+func TestParseRootAppMain(t *testing.T) {
+	appMain, err := parseRootAppMain(loadQuotePage(t, "quote-root-app-main.html"))
+	if err != nil {
+		t.Fatalf("parseRootAppMain() error = %v", err)
+	}
+
+	store := appMain.Context.Dispatcher.Stores.QuoteSummaryStore
+	if store.Price == nil || store.Price.Symbol != "SHOP.TO" || store.QuoteType == nil {
+		t.Errorf("parseRootAppMain() store = %+v, want the SHOP.TO price and quote type", store)
+	}
+
+	if _, err := parseRootAppMain(loadQuotePage(t, "quote-reactid-span.html")); err == nil {
+		t.Errorf("parseRootAppMain() of a page without the script, want an error")
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		txt      string
+		wantLow  float64
+		wantHigh float64
+		wantErr  bool
+	}{
+		{txt: "148.00 - 151.25", wantLow: 148, wantHigh: 151.25},
+		{txt: "1,024.50 - 1,100.00", wantLow: 1024.5, wantHigh: 1100},
+		{txt: "148.00", wantErr: true},
+		{txt: "N/A - 151.25", wantErr: true},
+		{txt: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		low, high, err := parseRange(tt.txt)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRange(%q) error = %v, wantErr %v", tt.txt, err, tt.wantErr)
+			continue
+		}
+
+		if low != tt.wantLow || high != tt.wantHigh {
+			t.Errorf("parseRange(%q) = %v, %v, want %v, %v", tt.txt, low, high, tt.wantLow, tt.wantHigh)
+		}
+	}
+}
+
+func TestParseAbbreviatedNumber(t *testing.T) {
+	tests := []struct {
+		txt     string
+		want    float64
+		wantErr bool
+	}{
+		{txt: "2.5T", want: 2.5e12},
+		{txt: "130B", want: 130e9},
+		{txt: "1.5M", want: 1.5e6},
+		{txt: "250k", want: 250e3},
+		{txt: "56,725,385", want: 56725385},
+		{txt: " 12 ", want: 12},
+		{txt: "N/A", wantErr: true},
+		{txt: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAbbreviatedNumber(tt.txt)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAbbreviatedNumber(%q) error = %v, wantErr %v", tt.txt, err, tt.wantErr)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("parseAbbreviatedNumber(%q) = %v, want %v", tt.txt, got, tt.want)
+		}
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	for txt, want := range map[string]float64{"(+1.23%)": 1.23, "(-0.94%)": -0.94, "0.5%": 0.5} {
+		got, err := parsePercent(txt)
+		if err != nil || got != want {
+			t.Errorf("parsePercent(%q) = %v, %v, want %v", txt, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/gocolly/colly/extensions"
+	"github.com/google/uuid"
+	corid "github.com/lenoobz/aws-lambda-corid"
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/symbols"
+)
+
+// YahooHTMLSource struct
+type YahooHTMLSource struct {
+	log logger.ContextLog
+}
+
+// NewYahooHTMLSource create new yahoo html price source
+func NewYahooHTMLSource(log logger.ContextLog) *YahooHTMLSource {
+	return &YahooHTMLSource{
+		log: log,
+	}
+}
+
+// newScraperJob creates a new colly collector with some custom configs
+func newScraperJob() *colly.Collector {
+	c := colly.NewCollector(
+		colly.AllowedDomains(config.AllowDomain),
+		colly.Async(true),
+	)
+
+	// Overrides the default timeout (10 seconds) for this collector
+	c.SetRequestTimeout(30 * time.Second)
+
+	// Limit the number of threads started by colly to two
+	// when visiting links which domains' matches "*httpbin.*" glob
+	c.Limit(&colly.LimitRule{
+		DomainGlob:  config.DomainGlob,
+		Parallelism: 2,
+		RandomDelay: 2 * time.Second,
+	})
+
+	extensions.RandomUserAgent(c)
+	extensions.Referer(c)
+
+	return c
+}
+
+// Name returns the source name
+func (s *YahooHTMLSource) Name() string {
+	return consts.YAHOO_HTML_SOURCE
+}
+
+// FetchAssetPrices scrapes yahoo quote pages of the given assets
+func (s *YahooHTMLSource) FetchAssetPrices(ctx context.Context, assets []*entities.Asset) ([]*entities.AssetPrice, map[string]error) {
+	job := &yahooHTMLJob{
+		source:   s,
+		failures: map[string]error{},
+	}
+
+	scrapePriceJob := newScraperJob()
+	scrapePriceJob.OnError(job.errorHandler)
+	scrapePriceJob.OnScraped(job.scrapedHandler)
+	scrapePriceJob.OnHTML("html", job.processPriceResponse)
+
+	for _, asset := range assets {
+		reqContext := colly.NewContext()
+		symbol := symbols.YahooSymbol(asset)
+		reqContext.Put("ticker", asset.Ticker)
+		reqContext.Put("symbol", symbol)
+		reqContext.Put("currency", asset.Currency)
+		reqContext.Put("quoteType", symbols.QuoteType(asset))
+
+		url := config.GetPriceByTickerURL(symbol)
+
+		s.log.Info(ctx, "scraping asset price", "ticker", asset.Ticker, "symbol", symbol)
+		if err := scrapePriceJob.Request("GET", url, nil, reqContext, nil); err != nil {
+			s.log.Error(ctx, "scraping asset price failed", "error", err, "ticker", asset.Ticker)
+			job.addFailure(asset.Ticker, &price.FetchError{Class: consts.NETWORK_ERROR, Err: err})
+		}
+	}
+
+	scrapePriceJob.Wait()
+
+	return job.prices, job.failures
+}
+
+///////////////////////////////////////////////////////////
+// Scraper Handler
+///////////////////////////////////////////////////////////
+
+// yahooHTMLJob collects the results of one FetchAssetPrices call
+type yahooHTMLJob struct {
+	source   *YahooHTMLSource
+	mu       sync.Mutex
+	prices   []*entities.AssetPrice
+	failures map[string]error
+}
+
+// addPrice records a scraped price
+func (j *yahooHTMLJob) addPrice(assetPrice *entities.AssetPrice) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.prices = append(j.prices, assetPrice)
+}
+
+// addFailure records a ticker that could not be scraped
+func (j *yahooHTMLJob) addFailure(ticker string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.failures[ticker] = err
+}
+
+// errorHandler generic error handler for all scaper jobs
+func (j *yahooHTMLJob) errorHandler(r *colly.Response, err error) {
+	ctx := context.Background()
+	j.source.log.Error(ctx, "failed to request url", "url", r.Request.URL, "error", err)
+
+	class := consts.HTTP_ERROR
+	switch r.StatusCode {
+	case 0:
+		class = consts.NETWORK_ERROR
+	case http.StatusNotFound:
+		class = consts.NOT_FOUND_ERROR
+	case http.StatusUnauthorized, http.StatusForbidden:
+		class = consts.AUTH_ERROR
+	}
+
+	j.addFailure(r.Request.Ctx.Get("ticker"), &price.FetchError{
+		Class:       class,
+		Status:      r.StatusCode,
+		SnippetHash: snippetHash(r.Body),
+		Err:         err,
+	})
+}
+
+func (j *yahooHTMLJob) scrapedHandler(r *colly.Response) {
+	ctx := context.Background()
+	foundPrice := r.Ctx.Get("foundPrice")
+	if foundPrice != "" {
+		return
+	}
+
+	ticker := r.Request.Ctx.Get("ticker")
+	requested := r.Request.Ctx.Get("symbol")
+	fetchErr := &price.FetchError{
+		Class:       consts.PARSE_ERROR,
+		Status:      r.StatusCode,
+		SnippetHash: snippetHash(r.Body),
+		Err:         fmt.Errorf("price not found"),
+	}
+
+	// yahoo redirects unknown symbols to the symbol lookup page and renamed ones to their new quote page
+	if isLookupPage(r.Request.URL) {
+		fetchErr.Class = consts.LOOKUP_ERROR
+		fetchErr.Err = fmt.Errorf("redirected to symbol lookup page")
+	} else if symbol := quotePageSymbol(r.Request.URL); symbol != "" && !strings.EqualFold(symbol, requested) {
+		fetchErr.Class = consts.RENAMED_ERROR
+		fetchErr.Successor = symbol
+		fetchErr.Err = fmt.Errorf("redirected to symbol %s", symbol)
+	}
+
+	j.source.log.Error(ctx, "price not found", "ticker", ticker, "url", r.Request.URL.String(), "errorClass", fetchErr.Class)
+	j.addFailure(ticker, fetchErr)
+}
+
+func (j *yahooHTMLJob) processPriceResponse(e *colly.HTMLElement) {
+	// create correlation if for processing fund list
+	id, _ := uuid.NewRandom()
+	ctx := corid.NewContext(context.Background(), id)
+
+	ticker := e.Request.Ctx.Get("ticker")
+	requested := e.Request.Ctx.Get("symbol")
+	currency := e.Request.Ctx.Get("currency")
+	quoteType := e.Request.Ctx.Get("quoteType")
+	j.source.log.Info(ctx, "processPriceResponse", "ticker", ticker)
+
+	// the price of another symbol must not be stored under this ticker
+	if symbol := quotePageSymbol(e.Request.URL); !strings.EqualFold(symbol, requested) {
+		j.source.log.Error(ctx, "not the quote page of the ticker", "ticker", ticker, "url", e.Request.URL.String())
+		return
+	}
+
+	assetPrice := entities.AssetPrice{
+		Ticker:   ticker,
+		Currency: currency,
+		Source:   j.source.Name(),
+	}
+
+	extractor, err := extractPrice(e.DOM, requested, &assetPrice)
+	if err != nil {
+		j.source.log.Error(ctx, "extract price failed", "error", err, "ticker", ticker)
+		return
+	}
+
+	j.source.log.Info(ctx, "price extracted", "ticker", ticker, "extractor", extractor)
+	assetPrice.Extractor = extractor
+
+	fillQuoteDetails(e.DOM, requested, quoteType, &assetPrice)
+
+	// the asset currency is only a fallback for pages that do not show one
+	if pageCurrency := extractCurrency(e.DOM); pageCurrency != "" {
+		currency, factor := currencyUnit(pageCurrency)
+		assetPrice.Currency = currency
+		toMainUnit(&assetPrice, factor)
+	}
+
+	if quoteType == consts.INDEX_QUOTE_TYPE {
+		assetPrice.Currency = ""
+	}
+
+	e.Response.Ctx.Put("foundPrice", "true")
+	j.addPrice(&assetPrice)
+}
+
+// quotePageSymbol returns the symbol of a yahoo quote page url, empty when it is not a quote page
+func quotePageSymbol(u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "quote" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// isLookupPage reports whether the url is the yahoo symbol lookup page
+func isLookupPage(u *url.URL) bool {
+	return strings.HasPrefix(strings.Trim(u.Path, "/"), "lookup")
+}
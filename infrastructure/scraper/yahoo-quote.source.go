@@ -0,0 +1,346 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/symbols"
+)
+
+// YahooQuoteSource struct
+type YahooQuoteSource struct {
+	*yahooClient
+	batchSize int
+	log       logger.ContextLog
+}
+
+// quoteResponse is the payload returned by the batch quote endpoint
+type quoteResponse struct {
+	QuoteResponse struct {
+		Result []*yahooQuote `json:"result"`
+		Error  *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteResponse"`
+}
+
+// yahooQuote is a single quote of the batch quote endpoint
+type yahooQuote struct {
+	Symbol                     string  `json:"symbol"`
+	ShortName                  string  `json:"shortName"`
+	LongName                   string  `json:"longName"`
+	QuoteType                  string  `json:"quoteType"`
+	Currency                   string  `json:"currency"`
+	Exchange                   string  `json:"exchange"`
+	RegularMarketPrice         float64 `json:"regularMarketPrice"`
+	RegularMarketTime          int64   `json:"regularMarketTime"`
+	MarketState                string  `json:"marketState"`
+	ExchangeTimezoneName       string  `json:"exchangeTimezoneName"`
+	RegularMarketPreviousClose float64 `json:"regularMarketPreviousClose"`
+	RegularMarketOpen          float64 `json:"regularMarketOpen"`
+	RegularMarketDayHigh       float64 `json:"regularMarketDayHigh"`
+	RegularMarketDayLow        float64 `json:"regularMarketDayLow"`
+	FiftyTwoWeekHigh           float64 `json:"fiftyTwoWeekHigh"`
+	FiftyTwoWeekLow            float64 `json:"fiftyTwoWeekLow"`
+	RegularMarketVolume        int64   `json:"regularMarketVolume"`
+	AverageDailyVolume3Month   int64   `json:"averageDailyVolume3Month"`
+	MarketCap                  int64   `json:"marketCap"`
+	Bid                        float64 `json:"bid"`
+	Ask                        float64 `json:"ask"`
+	RegularMarketChange        float64 `json:"regularMarketChange"`
+	RegularMarketChangePercent float64 `json:"regularMarketChangePercent"`
+	PreMarketPrice             float64 `json:"preMarketPrice"`
+	PreMarketChange            float64 `json:"preMarketChange"`
+	PreMarketChangePercent     float64 `json:"preMarketChangePercent"`
+	PreMarketTime              int64   `json:"preMarketTime"`
+	PostMarketPrice            float64 `json:"postMarketPrice"`
+	PostMarketChange           float64 `json:"postMarketChange"`
+	PostMarketChangePercent    float64 `json:"postMarketChangePercent"`
+	PostMarketTime             int64   `json:"postMarketTime"`
+}
+
+// NewYahooQuoteSource create new yahoo json quote price source
+func NewYahooQuoteSource(conf *config.ScraperConfig, log logger.ContextLog) (*YahooQuoteSource, error) {
+	client, err := newYahooClient(conf, log)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := conf.QuoteBatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	return &YahooQuoteSource{
+		yahooClient: client,
+		batchSize:   batchSize,
+		log:         log,
+	}, nil
+}
+
+// Name returns the source name
+func (s *YahooQuoteSource) Name() string {
+	return consts.YAHOO_QUOTE_SOURCE
+}
+
+// FetchAssetPrices fetches quotes of the given assets from the yahoo batch quote endpoint
+func (s *YahooQuoteSource) FetchAssetPrices(ctx context.Context, assets []*entities.Asset) ([]*entities.AssetPrice, map[string]error) {
+	var assetPrices []*entities.AssetPrice
+
+	failures := s.forEachQuote(ctx, assets, func(asset *entities.Asset, quote *yahooQuote) error {
+		if quote.RegularMarketPrice == 0 {
+			return &price.FetchError{
+				Class: consts.PARSE_ERROR,
+				Err:   fmt.Errorf("price not found"),
+			}
+		}
+
+		assetPrices = append(assetPrices, s.toAssetPrice(asset, quote))
+		return nil
+	})
+
+	return assetPrices, failures
+}
+
+// FetchAssetMetadata fetches the name, type, currency and exchange yahoo reports for the given assets
+func (s *YahooQuoteSource) FetchAssetMetadata(ctx context.Context, assets []*entities.Asset) ([]*entities.AssetMetadata, map[string]error) {
+	var metadata []*entities.AssetMetadata
+
+	failures := s.forEachQuote(ctx, assets, func(asset *entities.Asset, quote *yahooQuote) error {
+		name := quote.LongName
+		if name == "" {
+			name = quote.ShortName
+		}
+
+		currency, _ := currencyUnit(quote.Currency)
+
+		metadata = append(metadata, &entities.AssetMetadata{
+			Ticker:   asset.Ticker,
+			Symbol:   strings.ToUpper(quote.Symbol),
+			Name:     name,
+			Type:     quote.QuoteType,
+			Currency: currency,
+			Exchange: quote.Exchange,
+		})
+		return nil
+	})
+
+	return metadata, failures
+}
+
+// forEachQuote fetches the quotes of the given assets in batches and hands each asset and its quote
+// to handle. Returns the assets without a quote, or whose quote handle rejected, keyed by ticker
+func (s *YahooQuoteSource) forEachQuote(ctx context.Context, assets []*entities.Asset, handle func(asset *entities.Asset, quote *yahooQuote) error) map[string]error {
+	failures := map[string]error{}
+
+	for start := 0; start < len(assets); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(assets) {
+			end = len(assets)
+		}
+		batch := assets[start:end]
+
+		// quotes are keyed by symbol, assets are looked up by the symbol we requested. Tickers
+		// mapping to the same symbol share its quote, the symbol is only requested once
+		assetsBySymbol := map[string][]*entities.Asset{}
+		var batchSymbols []string
+		for _, asset := range batch {
+			symbol := strings.ToUpper(symbols.YahooSymbol(asset))
+			if _, ok := assetsBySymbol[symbol]; !ok {
+				batchSymbols = append(batchSymbols, symbol)
+			}
+			assetsBySymbol[symbol] = append(assetsBySymbol[symbol], asset)
+		}
+
+		s.log.Info(ctx, "fetching quotes", "symbols", batchSymbols)
+		quotes, err := s.fetchQuotes(ctx, batchSymbols)
+		if err != nil {
+			s.log.Error(ctx, "fetch quotes failed", "error", err, "symbols", batchSymbols)
+			for _, asset := range batch {
+				failures[asset.Ticker] = err
+			}
+			continue
+		}
+
+		for _, quote := range quotes {
+			symbol := strings.ToUpper(quote.Symbol)
+			symbolAssets, ok := assetsBySymbol[symbol]
+			if !ok {
+				continue
+			}
+			delete(assetsBySymbol, symbol)
+
+			for _, asset := range symbolAssets {
+				if err := handle(asset, quote); err != nil {
+					failures[asset.Ticker] = err
+				}
+			}
+		}
+
+		// a symbol missing from a response of its own is not found, one missing from a larger
+		// batch may have been dropped by a throttled response and is requested again alone
+		for _, symbol := range batchSymbols {
+			if symbolAssets, ok := assetsBySymbol[symbol]; ok {
+				s.confirmMissingQuote(ctx, symbol, len(batchSymbols) > 1, symbolAssets, handle, failures)
+			}
+		}
+	}
+
+	return failures
+}
+
+// confirmMissingQuote hands the assets of a symbol missing from a quote response to handle once
+// the symbol requested alone is quoted, when requestAlone is set. The assets of a symbol yahoo
+// does not quote are not found, those whose request fails get its error to be retried
+func (s *YahooQuoteSource) confirmMissingQuote(ctx context.Context, symbol string, requestAlone bool, symbolAssets []*entities.Asset, handle func(asset *entities.Asset, quote *yahooQuote) error, failures map[string]error) {
+	var quote *yahooQuote
+	if requestAlone {
+		s.log.Info(ctx, "symbol missing from quote response, requesting it alone", "symbol", symbol)
+		quotes, err := s.fetchQuotes(ctx, []string{symbol})
+		if err != nil {
+			s.log.Error(ctx, "fetch quote failed", "error", err, "symbol", symbol)
+			for _, asset := range symbolAssets {
+				failures[asset.Ticker] = err
+			}
+			return
+		}
+
+		for _, q := range quotes {
+			if strings.ToUpper(q.Symbol) == symbol {
+				quote = q
+			}
+		}
+	}
+
+	for _, asset := range symbolAssets {
+		if quote == nil {
+			failures[asset.Ticker] = &price.FetchError{
+				Class: consts.NOT_FOUND_ERROR,
+				Err:   fmt.Errorf("symbol not found in quote response"),
+			}
+			continue
+		}
+
+		if err := handle(asset, quote); err != nil {
+			failures[asset.Ticker] = err
+		}
+	}
+}
+
+// toAssetPrice maps a yahoo quote to an asset price
+func (s *YahooQuoteSource) toAssetPrice(asset *entities.Asset, quote *yahooQuote) *entities.AssetPrice {
+	quoteType := assetQuoteType(asset, quote.QuoteType)
+	currency, factor := quoteCurrency(asset, quoteType, quote.Currency)
+
+	assetPrice := &entities.AssetPrice{
+		Ticker:                  asset.Ticker,
+		Price:                   quote.RegularMarketPrice,
+		Currency:                currency,
+		Exchange:                quote.Exchange,
+		MarketTime:              quote.RegularMarketTime,
+		MarketState:             normalizeMarketState(quote.MarketState),
+		ExchangeTimezone:        quote.ExchangeTimezoneName,
+		PreviousClose:           quote.RegularMarketPreviousClose,
+		Open:                    quote.RegularMarketOpen,
+		DayHigh:                 quote.RegularMarketDayHigh,
+		DayLow:                  quote.RegularMarketDayLow,
+		FiftyTwoWeekHigh:        quote.FiftyTwoWeekHigh,
+		FiftyTwoWeekLow:         quote.FiftyTwoWeekLow,
+		Volume:                  quote.RegularMarketVolume,
+		AverageVolume:           quote.AverageDailyVolume3Month,
+		MarketCap:               quote.MarketCap,
+		Bid:                     quote.Bid,
+		Ask:                     quote.Ask,
+		Change:                  quote.RegularMarketChange,
+		ChangePercent:           quote.RegularMarketChangePercent,
+		PreMarketPrice:          quote.PreMarketPrice,
+		PreMarketChange:         quote.PreMarketChange,
+		PreMarketChangePercent:  quote.PreMarketChangePercent,
+		PreMarketTime:           quote.PreMarketTime,
+		PostMarketPrice:         quote.PostMarketPrice,
+		PostMarketChange:        quote.PostMarketChange,
+		PostMarketChangePercent: quote.PostMarketChangePercent,
+		PostMarketTime:          quote.PostMarketTime,
+		Source:                  s.Name(),
+	}
+
+	toMainUnit(assetPrice, factor)
+
+	if quoteType == consts.MUTUAL_FUND_QUOTE_TYPE && quote.RegularMarketTime > 0 {
+		assetPrice.NavDate = navDate(quote.RegularMarketTime, quote.ExchangeTimezoneName)
+	}
+
+	return assetPrice
+}
+
+// fetchQuotes fetches quotes of a batch of symbols, refreshing the crumb once if it was rejected
+func (s *YahooQuoteSource) fetchQuotes(ctx context.Context, symbols []string) ([]*yahooQuote, error) {
+	crumb, err := s.getCrumb(ctx, false)
+	if err != nil {
+		return nil, &price.FetchError{Class: consts.AUTH_ERROR, Err: err}
+	}
+
+	quotes, err := s.requestQuotes(ctx, symbols, crumb)
+	if !isAuthError(err) {
+		return quotes, err
+	}
+
+	s.log.Info(ctx, "crumb rejected, refreshing crumb")
+	crumb, err = s.getCrumb(ctx, true)
+	if err != nil {
+		return nil, &price.FetchError{Class: consts.AUTH_ERROR, Err: err}
+	}
+
+	return s.requestQuotes(ctx, symbols, crumb)
+}
+
+// requestQuotes calls the batch quote endpoint
+func (s *YahooQuoteSource) requestQuotes(ctx context.Context, symbols []string, crumb string) ([]*yahooQuote, error) {
+	body, status, err := s.get(ctx, config.GetQuotesURL(s.queryURL, symbols, crumb))
+	if err != nil {
+		return nil, &price.FetchError{Class: consts.NETWORK_ERROR, Status: status, Err: err}
+	}
+
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return nil, &price.FetchError{
+			Class:       consts.AUTH_ERROR,
+			Status:      status,
+			SnippetHash: snippetHash(body),
+			Err:         fmt.Errorf("crumb rejected"),
+		}
+	}
+
+	if status != http.StatusOK {
+		return nil, &price.FetchError{
+			Class:       consts.HTTP_ERROR,
+			Status:      status,
+			SnippetHash: snippetHash(body),
+			Err:         fmt.Errorf("unexpected status code %d", status),
+		}
+	}
+
+	var resp quoteResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, &price.FetchError{Class: consts.PARSE_ERROR, Status: status, SnippetHash: snippetHash(body), Err: err}
+	}
+
+	if resp.QuoteResponse.Error != nil {
+		return nil, &price.FetchError{
+			Class:       consts.HTTP_ERROR,
+			Status:      status,
+			SnippetHash: snippetHash(body),
+			Err:         fmt.Errorf("quote response error %s: %s", resp.QuoteResponse.Error.Code, resp.QuoteResponse.Error.Description),
+		}
+	}
+
+	return resp.QuoteResponse.Result, nil
+}
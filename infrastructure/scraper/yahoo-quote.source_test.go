@@ -0,0 +1,337 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+// fakeYahoo stands in for the yahoo cookie, crumb and batch quote endpoints
+type fakeYahoo struct {
+	mu sync.Mutex
+	// quotes are the quotes the quote endpoint knows, keyed by symbol
+	quotes map[string]map[string]interface{}
+	// rejectCrumbs are the crumbs the quote endpoint answers 401 to
+	rejectCrumbs map[string]bool
+	// dropFromBatches are the symbols left out of responses to more than one symbol, as a
+	// throttled response does
+	dropFromBatches map[string]bool
+	// throttleAlone are the symbols whose request alone is answered 429
+	throttleAlone map[string]bool
+	crumbs        int
+	batches       [][]string
+}
+
+// newFakeYahoo starts a yahoo stand in and returns a quote source pointed at it
+func newFakeYahoo(t *testing.T, batchSize int, quotes map[string]map[string]interface{}) (*fakeYahoo, *YahooQuoteSource) {
+	t.Helper()
+
+	fake := &fakeYahoo{
+		quotes:          quotes,
+		rejectCrumbs:    map[string]bool{},
+		dropFromBatches: map[string]bool{},
+		throttleAlone:   map[string]bool{},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(srv.Close)
+
+	source, err := NewYahooQuoteSource(&config.ScraperConfig{QuoteBatchSize: batchSize, TimeoutMS: 5000}, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewYahooQuoteSource() error = %v", err)
+	}
+
+	source.cookieURL = srv.URL + "/cookie"
+	source.queryURL = srv.URL
+	return fake, source
+}
+
+func (f *fakeYahoo) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/cookie":
+		http.SetCookie(w, &http.Cookie{Name: "A3", Value: "session"})
+		w.WriteHeader(http.StatusNotFound)
+	case "/v1/test/getcrumb":
+		f.crumbs++
+		fmt.Fprintf(w, "crumb-%d", f.crumbs)
+	case "/v7/finance/quote":
+		if f.rejectCrumbs[r.URL.Query().Get("crumb")] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		requested := strings.Split(r.URL.Query().Get("symbols"), ",")
+		f.batches = append(f.batches, requested)
+
+		if len(requested) == 1 && f.throttleAlone[requested[0]] {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		result := []map[string]interface{}{}
+		for _, symbol := range requested {
+			if len(requested) > 1 && f.dropFromBatches[symbol] {
+				continue
+			}
+
+			if quote, ok := f.quotes[symbol]; ok {
+				result = append(result, quote)
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"quoteResponse": map[string]interface{}{"result": result},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestLogger creates the logger of a test
+func newTestLogger(t *testing.T) logger.ContextLog {
+	t.Helper()
+
+	zap, err := logger.NewZapLogger()
+	if err != nil {
+		t.Fatalf("NewZapLogger() error = %v", err)
+	}
+	t.Cleanup(func() { zap.Close() })
+
+	return zap
+}
+
+// testQuote returns a quote of the symbol with a price
+func testQuote(symbol string, price float64) map[string]interface{} {
+	return map[string]interface{}{
+		"symbol":             symbol,
+		"currency":           "USD",
+		"regularMarketPrice": price,
+	}
+}
+
+func TestYahooQuoteSourceBatchesSymbols(t *testing.T) {
+	quotes := map[string]map[string]interface{}{}
+	var assets []*entities.Asset
+	for _, ticker := range []string{"AAA", "BBB", "CCC", "DDD", "EEE"} {
+		quotes[ticker] = testQuote(ticker, 10)
+		assets = append(assets, &entities.Asset{Ticker: ticker})
+	}
+
+	fake, source := newFakeYahoo(t, 2, quotes)
+
+	prices, failures := source.FetchAssetPrices(context.Background(), assets)
+	if len(failures) != 0 {
+		t.Fatalf("FetchAssetPrices() failures = %v, want none", failures)
+	}
+
+	if len(prices) != len(assets) {
+		t.Errorf("FetchAssetPrices() got %d prices, want %d", len(prices), len(assets))
+	}
+
+	var sizes []int
+	for _, batch := range fake.batches {
+		sizes = append(sizes, len(batch))
+	}
+
+	if fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Errorf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+
+	if fake.crumbs != 1 {
+		t.Errorf("crumb requests = %d, want the crumb cached after the first", fake.crumbs)
+	}
+}
+
+func TestYahooQuoteSourceRefreshesRejectedCrumb(t *testing.T) {
+	fake, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"AAA": testQuote("AAA", 10),
+	})
+	fake.rejectCrumbs["crumb-1"] = true
+
+	prices, failures := source.FetchAssetPrices(context.Background(), []*entities.Asset{{Ticker: "AAA"}})
+	if len(failures) != 0 {
+		t.Fatalf("FetchAssetPrices() failures = %v, want none", failures)
+	}
+
+	if len(prices) != 1 || prices[0].Price != 10 {
+		t.Errorf("FetchAssetPrices() prices = %v, want AAA at 10", prices)
+	}
+
+	if fake.crumbs != 2 {
+		t.Errorf("crumb requests = %d, want 2", fake.crumbs)
+	}
+
+	if source.crumb != "crumb-2" {
+		t.Errorf("cached crumb = %q, want crumb-2", source.crumb)
+	}
+}
+
+func TestYahooQuoteSourceReportsMissingSymbols(t *testing.T) {
+	fake, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"AAA": testQuote("AAA", 10),
+	})
+
+	prices, failures := source.FetchAssetPrices(context.Background(), []*entities.Asset{{Ticker: "AAA"}, {Ticker: "ZZZ"}})
+	if len(prices) != 1 {
+		t.Errorf("FetchAssetPrices() got %d prices, want 1", len(prices))
+	}
+
+	var fetchErr *price.FetchError
+	if !errors.As(failures["ZZZ"], &fetchErr) || fetchErr.Class != consts.NOT_FOUND_ERROR {
+		t.Errorf("failure of ZZZ = %v, want a %s fetch error", failures["ZZZ"], consts.NOT_FOUND_ERROR)
+	}
+
+	if _, ok := failures["AAA"]; ok {
+		t.Errorf("failure of AAA = %v, want none", failures["AAA"])
+	}
+
+	if fmt.Sprint(fake.batches) != "[[AAA ZZZ] [ZZZ]]" {
+		t.Errorf("requested batches = %v, want ZZZ confirmed alone", fake.batches)
+	}
+}
+
+func TestYahooQuoteSourceRequestsDroppedSymbolsAlone(t *testing.T) {
+	fake, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"AAA": testQuote("AAA", 10),
+		"BBB": testQuote("BBB", 20),
+	})
+	fake.dropFromBatches["BBB"] = true
+
+	prices, failures := source.FetchAssetPrices(context.Background(), []*entities.Asset{{Ticker: "AAA"}, {Ticker: "BBB"}})
+	if len(failures) != 0 {
+		t.Fatalf("FetchAssetPrices() failures = %v, want none", failures)
+	}
+
+	if len(prices) != 2 {
+		t.Errorf("FetchAssetPrices() got %d prices, want 2", len(prices))
+	}
+
+	if fmt.Sprint(fake.batches) != "[[AAA BBB] [BBB]]" {
+		t.Errorf("requested batches = %v, want BBB requested again alone", fake.batches)
+	}
+}
+
+func TestYahooQuoteSourceRetriesDroppedSymbolsOnError(t *testing.T) {
+	fake, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"AAA": testQuote("AAA", 10),
+		"BBB": testQuote("BBB", 20),
+	})
+	fake.dropFromBatches["BBB"] = true
+	fake.throttleAlone["BBB"] = true
+
+	_, failures := source.FetchAssetPrices(context.Background(), []*entities.Asset{{Ticker: "AAA"}, {Ticker: "BBB"}})
+
+	var fetchErr *price.FetchError
+	if !errors.As(failures["BBB"], &fetchErr) || fetchErr.IsDelisted() {
+		t.Errorf("failure of BBB = %v, want a transient fetch error", failures["BBB"])
+	}
+}
+
+func TestYahooQuoteSourceSharesQuoteOfSameSymbol(t *testing.T) {
+	fake, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"BRK-B": testQuote("BRK-B", 400),
+	})
+
+	assets := []*entities.Asset{
+		{Ticker: "BRK.B", Exchange: "NYSE"},
+		{Ticker: "BRKB", YahooSymbol: "BRK-B"},
+	}
+
+	prices, failures := source.FetchAssetPrices(context.Background(), assets)
+	if len(failures) != 0 {
+		t.Fatalf("FetchAssetPrices() failures = %v, want none", failures)
+	}
+
+	if len(prices) != 2 {
+		t.Fatalf("FetchAssetPrices() got %d prices, want one per ticker", len(prices))
+	}
+
+	if len(fake.batches) != 1 || len(fake.batches[0]) != 1 {
+		t.Errorf("requested batches = %v, want BRK-B requested once", fake.batches)
+	}
+}
+
+func TestYahooQuoteSourceParsesQuoteFields(t *testing.T) {
+	_, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"SHOP.TO": {
+			"symbol":               "shop.to",
+			"currency":             "cad",
+			"exchange":             "TOR",
+			"regularMarketPrice":   101.5,
+			"regularMarketTime":    1695240000,
+			"marketState":          "POSTPOST",
+			"exchangeTimezoneName": "America/Toronto",
+			"postMarketPrice":      102.25,
+		},
+	})
+
+	prices, failures := source.FetchAssetPrices(context.Background(), []*entities.Asset{{Ticker: "SHOP", Exchange: "TSX", Currency: "USD"}})
+	if len(failures) != 0 || len(prices) != 1 {
+		t.Fatalf("FetchAssetPrices() = %v, %v, want one price", prices, failures)
+	}
+
+	got := prices[0]
+	if got.Ticker != "SHOP" {
+		t.Errorf("Ticker = %q, want the asset ticker SHOP", got.Ticker)
+	}
+
+	if got.Currency != "CAD" {
+		t.Errorf("Currency = %q, want the quoted CAD", got.Currency)
+	}
+
+	if got.MarketTime != 1695240000 {
+		t.Errorf("MarketTime = %d, want 1695240000", got.MarketTime)
+	}
+
+	if got.MarketState != consts.MARKET_STATE_CLOSED {
+		t.Errorf("MarketState = %q, want %s", got.MarketState, consts.MARKET_STATE_CLOSED)
+	}
+
+	if got.ExchangeTimezone != "America/Toronto" || got.Exchange != "TOR" {
+		t.Errorf("Exchange = %q %q, want TOR America/Toronto", got.Exchange, got.ExchangeTimezone)
+	}
+
+	if got.Price != 101.5 || got.PostMarketPrice != 102.25 {
+		t.Errorf("Price = %v post %v, want 101.5 post 102.25", got.Price, got.PostMarketPrice)
+	}
+
+	if got.Source != consts.YAHOO_QUOTE_SOURCE {
+		t.Errorf("Source = %q, want %s", got.Source, consts.YAHOO_QUOTE_SOURCE)
+	}
+}
+
+func TestYahooQuoteSourceConvertsMinorUnits(t *testing.T) {
+	_, source := newFakeYahoo(t, 50, map[string]map[string]interface{}{
+		"VOD.L": {
+			"symbol":                     "VOD.L",
+			"currency":                   "GBp",
+			"regularMarketPrice":         72.5,
+			"regularMarketPreviousClose": 73,
+		},
+	})
+
+	prices, failures := source.FetchAssetPrices(context.Background(), []*entities.Asset{{Ticker: "VOD", Exchange: "LSE"}})
+	if len(failures) != 0 || len(prices) != 1 {
+		t.Fatalf("FetchAssetPrices() = %v, %v, want one price", prices, failures)
+	}
+
+	got := prices[0]
+	if got.Currency != "GBP" || got.Price != 0.725 || got.PreviousClose != 0.73 {
+		t.Errorf("price = %v %s previous close %v, want 0.725 GBP previous close 0.73", got.Price, got.Currency, got.PreviousClose)
+	}
+}
@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// searchCount is the number of symbols a search returns at most
+const searchCount = 20
+
+// YahooSearchSource struct
+type YahooSearchSource struct {
+	quoteSource *YahooQuoteSource
+	log         logger.ContextLog
+}
+
+// searchResponse is the payload returned by the search endpoint
+type searchResponse struct {
+	Quotes []*yahooSearchQuote `json:"quotes"`
+}
+
+// yahooSearchQuote is a symbol found by the search endpoint
+type yahooSearchQuote struct {
+	Symbol    string `json:"symbol"`
+	ShortName string `json:"shortname"`
+	LongName  string `json:"longname"`
+	Exchange  string `json:"exchange"`
+	ExchDisp  string `json:"exchDisp"`
+	QuoteType string `json:"quoteType"`
+}
+
+// NewYahooSearchSource create new yahoo symbol search source
+func NewYahooSearchSource(conf *config.ScraperConfig, log logger.ContextLog) (*YahooSearchSource, error) {
+	quoteSource, err := NewYahooQuoteSource(conf, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &YahooSearchSource{
+		quoteSource: quoteSource,
+		log:         log,
+	}, nil
+}
+
+// SearchSymbols searches yahoo for the symbols matching a name, a symbol or an ISIN. The search
+// does not return currencies, they are taken from the quotes of the found symbols
+func (s *YahooSearchSource) SearchSymbols(ctx context.Context, query string) ([]*entities.SymbolCandidate, error) {
+	body, status, err := s.quoteSource.get(ctx, config.GetSearchURL(s.quoteSource.queryURL, query, searchCount))
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", status)
+	}
+
+	var resp searchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	var candidates []*entities.SymbolCandidate
+	var symbols []string
+	for _, quote := range resp.Quotes {
+		// news and other results without a symbol are left out
+		if quote.Symbol == "" {
+			continue
+		}
+
+		name := quote.LongName
+		if name == "" {
+			name = quote.ShortName
+		}
+
+		candidates = append(candidates, &entities.SymbolCandidate{
+			Symbol:       strings.ToUpper(quote.Symbol),
+			Name:         name,
+			Exchange:     quote.Exchange,
+			ExchangeName: quote.ExchDisp,
+			Type:         quote.QuoteType,
+		})
+		symbols = append(symbols, strings.ToUpper(quote.Symbol))
+	}
+
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+
+	quotes, err := s.quoteSource.fetchQuotes(ctx, symbols)
+	if err != nil {
+		s.log.Error(ctx, "fetch quotes of found symbols failed", "error", err, "symbols", symbols)
+		return candidates, nil
+	}
+
+	currencies := map[string]string{}
+	for _, quote := range quotes {
+		currencies[strings.ToUpper(quote.Symbol)], _ = currencyUnit(quote.Currency)
+	}
+
+	for _, candidate := range candidates {
+		candidate.Currency = currencies[candidate.Symbol]
+	}
+
+	return candidates, nil
+}
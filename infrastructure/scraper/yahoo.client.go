@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/config"
+)
+
+// userAgent is sent with every request, yahoo rejects requests from the default go client
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.93 Safari/537.36"
+
+// yahooClient is the http client shared by the sources calling the yahoo json endpoints
+type yahooClient struct {
+	client    *http.Client
+	cookieURL string
+	queryURL  string
+	log       logger.ContextLog
+	mu        sync.Mutex
+	crumb     string
+}
+
+// newYahooClient creates a new yahoo client with its own cookie jar
+func newYahooClient(conf *config.ScraperConfig, log logger.ContextLog) (*yahooClient, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &yahooClient{
+		client: &http.Client{
+			Jar:     jar,
+			Timeout: time.Duration(conf.TimeoutMS) * time.Millisecond,
+		},
+		cookieURL: config.YahooCookieURL,
+		queryURL:  config.YahooQueryURL,
+		log:       log,
+	}, nil
+}
+
+// getCrumb returns the cached crumb or gets a new cookie and crumb from yahoo
+func (c *yahooClient) getCrumb(ctx context.Context, refresh bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.crumb != "" && !refresh {
+		return c.crumb, nil
+	}
+
+	// the cookie endpoint answers with an error status but still sets the session cookie
+	if _, _, err := c.get(ctx, c.cookieURL); err != nil {
+		return "", err
+	}
+
+	body, status, err := c.get(ctx, config.GetCrumbURL(c.queryURL))
+	if err != nil {
+		return "", err
+	}
+
+	crumb := strings.TrimSpace(string(body))
+	if status != http.StatusOK || crumb == "" {
+		return "", fmt.Errorf("get crumb failed with status code %d", status)
+	}
+
+	c.crumb = crumb
+	return crumb, nil
+}
+
+// get sends a GET request and returns the response body and status code
+func (c *yahooClient) get(ctx context.Context, url string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,212 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// maxAllocation is the largest allocation total of an asset, in percent, rounding included
+const maxAllocation = 100.5
+
+// currencyPattern matches ISO 4217 currency codes
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ImportAssets validates the imported assets, compares them with the stored ones and, unless it is
+// a dry run, inserts the new assets and updates the changed ones. Nothing is written when a row is
+// invalid. Only the name, type, asset class, currency, exchange, yahoo symbol and allocations the
+// file provides are imported, the other fields are left as they are. Rows of soft deleted assets
+// are invalid, the asset has to be restored first
+func (s *Service) ImportAssets(ctx context.Context, imported []*entities.ImportedAsset, dryRun bool) (*entities.AssetImportReport, error) {
+	s.log.Info(ctx, "importing assets", "numAssets", len(imported), "dryRun", dryRun)
+
+	report := &entities.AssetImportReport{
+		DryRun:  dryRun,
+		Invalid: validateAssets(imported),
+	}
+
+	var tickers []string
+	for _, row := range imported {
+		asset := row.Asset
+		asset.Ticker = strings.ToUpper(strings.TrimSpace(asset.Ticker))
+		asset.Currency = strings.ToUpper(strings.TrimSpace(asset.Currency))
+		if asset.Ticker != "" {
+			tickers = append(tickers, asset.Ticker)
+		}
+	}
+
+	stored := map[string]*entities.Asset{}
+	if len(tickers) > 0 {
+		existing, err := s.assetRepo.FindAllAssets(ctx, &entities.AssetFilter{
+			IncludeDisabled:    true,
+			IncludeDeleted:     true,
+			IncludeQuarantined: true,
+			Tickers:            tickers,
+		})
+		if err != nil {
+			s.log.Error(ctx, "find stored assets failed", "error", err)
+			return nil, err
+		}
+
+		for _, asset := range existing {
+			stored[strings.ToUpper(asset.Ticker)] = asset
+		}
+	}
+
+	var changed []*entities.ImportedAsset
+	for i, row := range imported {
+		asset := row.Asset
+		if asset.Ticker == "" {
+			continue
+		}
+
+		old, ok := stored[asset.Ticker]
+		if !ok {
+			report.Inserted = append(report.Inserted, &entities.AssetChange{
+				Row:    i + 1,
+				Ticker: asset.Ticker,
+				Fields: diffAssets(&entities.Asset{}, asset, row.Fields),
+			})
+			changed = append(changed, row)
+			continue
+		}
+
+		// a deleted asset would stay deleted, it is not silently updated
+		if old.Deleted {
+			report.Invalid = append(report.Invalid, &entities.AssetImportError{
+				Row:    i + 1,
+				Ticker: asset.Ticker,
+				Reason: "asset is deleted, restore it first",
+			})
+			continue
+		}
+
+		fields := diffAssets(old, asset, row.Fields)
+		if len(fields) == 0 {
+			report.Unchanged = append(report.Unchanged, asset.Ticker)
+			continue
+		}
+
+		report.Updated = append(report.Updated, &entities.AssetChange{
+			Row:    i + 1,
+			Ticker: asset.Ticker,
+			Fields: fields,
+		})
+		changed = append(changed, row)
+	}
+
+	if len(report.Invalid) > 0 {
+		s.log.Error(ctx, "invalid assets", "numInvalid", len(report.Invalid))
+		return report, fmt.Errorf("%d invalid rows", len(report.Invalid))
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := s.assetRepo.UpsertAssets(ctx, changed); err != nil {
+		s.log.Error(ctx, "upsert assets failed", "error", err)
+		return report, err
+	}
+
+	s.log.Info(ctx, "imported assets", "inserted", len(report.Inserted), "updated", len(report.Updated), "unchanged", len(report.Unchanged))
+	return report, nil
+}
+
+// validateAssets checks the imported assets, a ticker is required and unique, the currency is a
+// currency code and allocations are percentages adding up to at most one hundred
+func validateAssets(imported []*entities.ImportedAsset) []*entities.AssetImportError {
+	var invalid []*entities.AssetImportError
+	rows := map[string]int{}
+
+	for i, importedAsset := range imported {
+		asset := importedAsset.Asset
+		row := i + 1
+		ticker := strings.ToUpper(strings.TrimSpace(asset.Ticker))
+
+		addError := func(format string, args ...interface{}) {
+			invalid = append(invalid, &entities.AssetImportError{
+				Row:    row,
+				Ticker: ticker,
+				Reason: fmt.Sprintf(format, args...),
+			})
+		}
+
+		if ticker == "" {
+			addError("ticker is empty")
+			continue
+		}
+
+		if strings.ContainsAny(ticker, " \t,") {
+			addError("ticker contains spaces or commas")
+		}
+
+		if first, ok := rows[ticker]; ok {
+			addError("duplicate of row %d", first)
+		} else {
+			rows[ticker] = row
+		}
+
+		currency := strings.ToUpper(strings.TrimSpace(asset.Currency))
+		if currency != "" && !currencyPattern.MatchString(currency) {
+			addError("invalid currency %q", asset.Currency)
+		}
+
+		allocations := []float64{asset.AllocationStock, asset.AllocationBond, asset.AllocationCash}
+		total := 0.0
+		for _, allocation := range allocations {
+			if allocation < 0 || allocation > 100 {
+				addError("allocation %g is not a percentage", allocation)
+			}
+			total += allocation
+		}
+
+		if total > maxAllocation {
+			addError("allocations add up to %g", total)
+		}
+	}
+
+	return invalid
+}
+
+// diffAssets returns the imported fields whose value differs from the stored asset, among the given
+// fields
+func diffAssets(old *entities.Asset, asset *entities.Asset, importedFields []string) []*entities.AssetFieldChange {
+	var fields []*entities.AssetFieldChange
+
+	imported := map[string]bool{}
+	for _, field := range importedFields {
+		imported[field] = true
+	}
+
+	diffField := func(field string, oldValue string, newValue string) {
+		if imported[field] && oldValue != newValue {
+			fields = append(fields, &entities.AssetFieldChange{
+				Field: field,
+				Old:   oldValue,
+				New:   newValue,
+			})
+		}
+	}
+
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	diffField(consts.ASSET_NAME_FIELD, old.Name, asset.Name)
+	diffField(consts.ASSET_TYPE_FIELD, old.Type, asset.Type)
+	diffField(consts.ASSET_CLASS_FIELD, old.AssetClass, asset.AssetClass)
+	diffField(consts.ASSET_CURRENCY_FIELD, strings.ToUpper(old.Currency), asset.Currency)
+	diffField(consts.ASSET_EXCHANGE_FIELD, strings.ToUpper(old.Exchange), strings.ToUpper(asset.Exchange))
+	diffField(consts.ASSET_YAHOO_SYMBOL_FIELD, strings.ToUpper(old.YahooSymbol), strings.ToUpper(asset.YahooSymbol))
+	diffField(consts.ASSET_ALLOCATION_STOCK_FIELD, formatFloat(old.AllocationStock), formatFloat(asset.AllocationStock))
+	diffField(consts.ASSET_ALLOCATION_BOND_FIELD, formatFloat(old.AllocationBond), formatFloat(asset.AllocationBond))
+	diffField(consts.ASSET_ALLOCATION_CASH_FIELD, formatFloat(old.AllocationCash), formatFloat(asset.AllocationCash))
+
+	return fields
+}
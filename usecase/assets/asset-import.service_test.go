@@ -0,0 +1,103 @@
+package assets
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/checkpoint"
+)
+
+// fakeAssetRepo holds the stored assets of an import and records the upserted ones, the methods
+// an import does not use are left to the nil Repo
+type fakeAssetRepo struct {
+	Repo
+	stored   []*entities.Asset
+	upserted []*entities.ImportedAsset
+}
+
+func (r *fakeAssetRepo) FindAllAssets(ctx context.Context, filter *entities.AssetFilter) ([]*entities.Asset, error) {
+	return r.stored, nil
+}
+
+func (r *fakeAssetRepo) UpsertAssets(ctx context.Context, assets []*entities.ImportedAsset) error {
+	r.upserted = append(r.upserted, assets...)
+	return nil
+}
+
+// newImportService creates an asset service over the stored assets
+func newImportService(t *testing.T, stored ...*entities.Asset) (*Service, *fakeAssetRepo) {
+	t.Helper()
+
+	zap, err := logger.NewZapLogger()
+	if err != nil {
+		t.Fatalf("NewZapLogger() error = %v", err)
+	}
+	t.Cleanup(func() { zap.Close() })
+
+	repo := &fakeAssetRepo{stored: stored}
+	return NewService(repo, checkpoint.Service{}, zap), repo
+}
+
+func TestImportAssetsDiffsProvidedFields(t *testing.T) {
+	service, repo := newImportService(t, &entities.Asset{
+		Ticker:          "XIU",
+		Name:            "iShares S&P/TSX 60",
+		Currency:        "CAD",
+		Exchange:        "TSX",
+		AllocationStock: 100,
+	})
+
+	// the file only provides the name, the other stored fields are not blanked
+	imported := []*entities.ImportedAsset{{
+		Asset:  &entities.Asset{Ticker: "xiu", Name: "iShares Core S&P/TSX 60"},
+		Fields: []string{consts.ASSET_NAME_FIELD},
+	}}
+
+	report, err := service.ImportAssets(context.Background(), imported, false)
+	if err != nil {
+		t.Fatalf("ImportAssets() error = %v", err)
+	}
+
+	if len(report.Updated) != 1 {
+		t.Fatalf("ImportAssets() updated = %v, want XIU", report.Updated)
+	}
+
+	want := []*entities.AssetFieldChange{{Field: consts.ASSET_NAME_FIELD, Old: "iShares S&P/TSX 60", New: "iShares Core S&P/TSX 60"}}
+	if !reflect.DeepEqual(report.Updated[0].Fields, want) {
+		t.Errorf("updated fields = %+v, want only the name", report.Updated[0].Fields)
+	}
+
+	if len(repo.upserted) != 1 || !reflect.DeepEqual(repo.upserted[0].Fields, []string{consts.ASSET_NAME_FIELD}) {
+		t.Errorf("upserted = %+v, want XIU with only the name", repo.upserted)
+	}
+}
+
+func TestImportAssetsRefusesDeletedAssets(t *testing.T) {
+	service, repo := newImportService(t, &entities.Asset{Ticker: "OLD", Name: "Old", Deleted: true})
+
+	imported := []*entities.ImportedAsset{
+		{Asset: &entities.Asset{Ticker: "OLD", Name: "Renamed"}, Fields: []string{consts.ASSET_NAME_FIELD}},
+		{Asset: &entities.Asset{Ticker: "NEW", Name: "New"}, Fields: []string{consts.ASSET_NAME_FIELD}},
+	}
+
+	report, err := service.ImportAssets(context.Background(), imported, false)
+	if err == nil {
+		t.Fatalf("ImportAssets() of a deleted asset, want an error")
+	}
+
+	if len(report.Invalid) != 1 || report.Invalid[0].Ticker != "OLD" || report.Invalid[0].Row != 1 {
+		t.Errorf("invalid = %+v, want row 1 OLD", report.Invalid)
+	}
+
+	if len(report.Updated) != 0 {
+		t.Errorf("updated = %+v, want the deleted asset not counted as updated", report.Updated)
+	}
+
+	if len(repo.upserted) != 0 {
+		t.Errorf("upserted = %+v, want nothing written", repo.upserted)
+	}
+}
@@ -12,13 +12,27 @@ import (
 
 // Reader interface
 type Reader interface {
-	CountAssets(ctx context.Context) (int64, error)
-	FindAllAssets(ctx context.Context) ([]*entities.Asset, error)
-	FindAssetsFromCheckpoint(ctx context.Context, checkpoint *entities.Checkpoint) ([]*entities.Asset, error)
+	CountAssets(ctx context.Context, filter *entities.AssetFilter) (int64, error)
+	FindAllAssets(ctx context.Context, filter *entities.AssetFilter) ([]*entities.Asset, error)
+	FindAssetsFromCheckpoint(ctx context.Context, checkpoint *entities.Checkpoint, filter *entities.AssetFilter) ([]*entities.Asset, error)
+	FindAssetsByTickers(ctx context.Context, tickers []string) ([]*entities.Asset, error)
+	FindQuarantinedAssets(ctx context.Context) ([]*entities.Asset, error)
 }
 
 // Writer interface
 type Writer interface {
+	InsertAsset(ctx context.Context, asset *entities.Asset) error
+	UpsertAssets(ctx context.Context, assets []*entities.ImportedAsset) error
+	UpdateAsset(ctx context.Context, asset *entities.Asset, fields []string) error
+	SoftDeleteAsset(ctx context.Context, ticker string) error
+	RestoreAsset(ctx context.Context, ticker string) error
+	SetAssetEnabled(ctx context.Context, ticker string, enabled bool) error
+	UpdateAssetDistribution(ctx context.Context, ticker string, distribution *entities.AssetDistribution) error
+	UpdateAssetMetadata(ctx context.Context, ticker string, metadata *entities.AssetMetadata, reportedCurrency string) error
+	QuarantineAsset(ctx context.Context, ticker string, reason string) error
+	ClearAssetQuarantine(ctx context.Context, tickers []string) (int64, error)
+	FlagAssetDelisted(ctx context.Context, ticker string, successor string) error
+	ClearAssetDelisted(ctx context.Context, tickers []string) error
 }
 
 // Repo interface
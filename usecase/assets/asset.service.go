@@ -2,6 +2,9 @@ package assets
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	logger "github.com/lenoobz/aws-lambda-logger"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
@@ -24,23 +27,32 @@ func NewService(assetRepo Repo, checkpointService checkpoint.Service, log logger
 	}
 }
 
-// GetAllAssets gets all assets
-func (s *Service) GetAllAssets(ctx context.Context) ([]*entities.Asset, error) {
-	s.log.Info(ctx, "getting all assets")
-	return s.assetRepo.FindAllAssets(ctx)
+// GetAllAssets gets all assets matching the filter, the enabled assets not deleted when it is nil
+func (s *Service) GetAllAssets(ctx context.Context, filter *entities.AssetFilter) ([]*entities.Asset, error) {
+	s.log.Info(ctx, "getting all assets", "filter", filter)
+	return s.assetRepo.FindAllAssets(ctx, filter)
 }
 
-// GetAssetsFromCheckpoint gets all assets from checkpoint
-func (s *Service) GetAssetsFromCheckpoint(ctx context.Context, pageSize int64) ([]*entities.Asset, error) {
-	s.log.Info(ctx, "getting assets from checkpoint")
-	numAssets, err := s.assetRepo.CountAssets(ctx)
+// GetAssetsByTickers gets assets of the given tickers
+func (s *Service) GetAssetsByTickers(ctx context.Context, tickers []string) ([]*entities.Asset, error) {
+	s.log.Info(ctx, "getting assets by tickers", "tickers", tickers)
+	return s.assetRepo.FindAssetsByTickers(ctx, tickers)
+}
+
+// GetAssetsFromCheckpoint gets the assets matching the filter from the checkpoint of the filter, see
+// CheckpointName. Only the matching assets are counted, so the checkpoint pages through them alone
+func (s *Service) GetAssetsFromCheckpoint(ctx context.Context, name string, pageSize int64, filter *entities.AssetFilter) ([]*entities.Asset, error) {
+	name = CheckpointName(name, filter)
+
+	s.log.Info(ctx, "getting assets from checkpoint", "name", name, "filter", filter)
+	numAssets, err := s.assetRepo.CountAssets(ctx, filter)
 	if err != nil {
 		s.log.Error(ctx, "count assets failed", "error", err)
 	}
 
-	checkpoint, err := s.checkpointService.UpdateCheckpoint(ctx, pageSize, numAssets)
+	checkpoint, err := s.checkpointService.LeaseCheckpoint(ctx, name, pageSize, numAssets)
 	if err != nil {
-		s.log.Error(ctx, "find and update checkpoint failed", "error", err)
+		s.log.Error(ctx, "lease checkpoint failed", "error", err)
 	}
 
 	if checkpoint == nil {
@@ -48,5 +60,157 @@ func (s *Service) GetAssetsFromCheckpoint(ctx context.Context, pageSize int64) (
 		return nil, nil
 	}
 
-	return s.assetRepo.FindAssetsFromCheckpoint(ctx, checkpoint)
+	return s.assetRepo.FindAssetsFromCheckpoint(ctx, checkpoint, filter)
+}
+
+// ReleaseCheckpoint releases the page of assets GetAssetsFromCheckpoint leased from the checkpoint
+// of the filter
+func (s *Service) ReleaseCheckpoint(ctx context.Context, name string, filter *entities.AssetFilter) error {
+	return s.checkpointService.ReleaseCheckpoint(ctx, CheckpointName(name, filter))
+}
+
+// GetCheckpointStatus gets how far the checkpoint of the filter is through the assets matching it
+func (s *Service) GetCheckpointStatus(ctx context.Context, name string, filter *entities.AssetFilter) (*entities.CheckpointStatus, error) {
+	numAssets, err := s.assetRepo.CountAssets(ctx, filter)
+	if err != nil {
+		s.log.Error(ctx, "count assets failed", "error", err)
+		return nil, err
+	}
+
+	return s.checkpointService.GetCheckpointStatus(ctx, CheckpointName(name, filter), numAssets)
+}
+
+// CheckpointName returns the name of the checkpoint paging through the assets matching the filter.
+// The pages of a filter only make sense for that filter, so a filter other than the default one
+// pages with its own checkpoint, named after the job checkpoint and the filter values
+func CheckpointName(name string, filter *entities.AssetFilter) string {
+	if filter == nil {
+		return name
+	}
+
+	var parts []string
+	addList := func(key string, values []string, upper bool) {
+		var trimmed []string
+		for _, value := range values {
+			value = strings.TrimSpace(value)
+			if upper {
+				value = strings.ToUpper(value)
+			}
+			if value != "" {
+				trimmed = append(trimmed, value)
+			}
+		}
+
+		if len(trimmed) > 0 {
+			sort.Strings(trimmed)
+			parts = append(parts, key+"="+strings.Join(trimmed, ","))
+		}
+	}
+
+	addList("types", filter.Types, false)
+	addList("assetClasses", filter.AssetClasses, false)
+	addList("currencies", filter.Currencies, true)
+	addList("tickers", filter.Tickers, true)
+
+	if filter.IncludeDisabled {
+		parts = append(parts, "includeDisabled")
+	}
+
+	if filter.IncludeDeleted {
+		parts = append(parts, "includeDeleted")
+	}
+
+	if filter.IncludeQuarantined {
+		parts = append(parts, "includeQuarantined")
+	}
+
+	if len(parts) == 0 {
+		return name
+	}
+
+	return name + ":" + strings.Join(parts, ";")
+}
+
+// AddAsset creates new asset
+func (s *Service) AddAsset(ctx context.Context, asset *entities.Asset) error {
+	s.log.Info(ctx, "adding asset", "ticker", asset.Ticker)
+
+	if strings.TrimSpace(asset.Ticker) == "" {
+		return fmt.Errorf("asset ticker is empty")
+	}
+
+	return s.assetRepo.InsertAsset(ctx, asset)
+}
+
+// UpdateAsset updates the given fields of an asset, among the name, type, class, currency, exchange,
+// yahoo symbol and allocations. The other fields are left as they are
+func (s *Service) UpdateAsset(ctx context.Context, asset *entities.Asset, fields ...string) error {
+	s.log.Info(ctx, "updating asset", "ticker", asset.Ticker, "fields", fields)
+
+	if len(fields) == 0 {
+		return fmt.Errorf("no asset fields to update")
+	}
+
+	return s.assetRepo.UpdateAsset(ctx, asset, fields)
+}
+
+// DeleteAsset soft deletes an asset, it is no longer scraped but its document is kept
+func (s *Service) DeleteAsset(ctx context.Context, ticker string) error {
+	s.log.Info(ctx, "deleting asset", "ticker", ticker)
+	return s.assetRepo.SoftDeleteAsset(ctx, ticker)
+}
+
+// RestoreAsset undoes the soft delete of an asset, it is enabled and scraped again
+func (s *Service) RestoreAsset(ctx context.Context, ticker string) error {
+	s.log.Info(ctx, "restoring asset", "ticker", ticker)
+	return s.assetRepo.RestoreAsset(ctx, ticker)
+}
+
+// SetAssetEnabled enables or disables scraping of an asset
+func (s *Service) SetAssetEnabled(ctx context.Context, ticker string, enabled bool) error {
+	s.log.Info(ctx, "setting asset enabled", "ticker", ticker, "enabled", enabled)
+	return s.assetRepo.SetAssetEnabled(ctx, ticker, enabled)
+}
+
+// UpdateAssetDistribution updates dividend schedule, yields and distribution amount of an asset
+func (s *Service) UpdateAssetDistribution(ctx context.Context, ticker string, distribution *entities.AssetDistribution) error {
+	s.log.Info(ctx, "updating asset distribution", "ticker", ticker)
+	return s.assetRepo.UpdateAssetDistribution(ctx, ticker, distribution)
+}
+
+// UpdateAssetMetadata sets the non empty metadata fields of an asset and flags the currency yahoo
+// reports when it differs from the asset currency, an empty reported currency clears the flag
+func (s *Service) UpdateAssetMetadata(ctx context.Context, ticker string, metadata *entities.AssetMetadata, reportedCurrency string) error {
+	s.log.Info(ctx, "updating asset metadata", "ticker", ticker, "reportedCurrency", reportedCurrency)
+	return s.assetRepo.UpdateAssetMetadata(ctx, ticker, metadata, reportedCurrency)
+}
+
+// GetQuarantinedAssets gets the quarantined assets
+func (s *Service) GetQuarantinedAssets(ctx context.Context) ([]*entities.Asset, error) {
+	s.log.Info(ctx, "getting quarantined assets")
+	return s.assetRepo.FindQuarantinedAssets(ctx)
+}
+
+// QuarantineAsset quarantines an asset so it is no longer scraped
+func (s *Service) QuarantineAsset(ctx context.Context, ticker string, reason string) error {
+	s.log.Info(ctx, "quarantining asset", "ticker", ticker, "reason", reason)
+	return s.assetRepo.QuarantineAsset(ctx, ticker, reason)
+}
+
+// ClearAssetQuarantine clears the quarantine of the given tickers
+func (s *Service) ClearAssetQuarantine(ctx context.Context, tickers []string) (int64, error) {
+	s.log.Info(ctx, "clearing asset quarantine", "tickers", tickers)
+	return s.assetRepo.ClearAssetQuarantine(ctx, tickers)
+}
+
+// FlagAssetDelisted flags an asset as suspected delisted, with the symbol it was renamed to if any
+func (s *Service) FlagAssetDelisted(ctx context.Context, ticker string, successor string) error {
+	s.log.Info(ctx, "flagging asset as suspected delisted", "ticker", ticker, "successor", successor)
+	return s.assetRepo.FlagAssetDelisted(ctx, ticker, successor)
+}
+
+// ClearAssetDelisted clears the suspected delisted flag of the given tickers
+func (s *Service) ClearAssetDelisted(ctx context.Context, tickers []string) error {
+	s.log.Info(ctx, "clearing suspected delisted flags", "numTickers", len(tickers))
+	return s.assetRepo.ClearAssetDelisted(ctx, tickers)
 }
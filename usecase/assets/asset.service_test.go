@@ -0,0 +1,42 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+func TestCheckpointName(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *entities.AssetFilter
+		want   string
+	}{
+		{name: "nil filter", filter: nil, want: "prices"},
+		{name: "default filter", filter: &entities.AssetFilter{}, want: "prices"},
+		{
+			name:   "lists sorted",
+			filter: &entities.AssetFilter{Currencies: []string{"usd", "CAD"}, Types: []string{"ETF"}},
+			want:   "prices:types=ETF;currencies=CAD,USD",
+		},
+		{
+			name:   "flags",
+			filter: &entities.AssetFilter{Tickers: []string{" xiu "}, IncludeDisabled: true, IncludeDeleted: true},
+			want:   "prices:tickers=XIU;includeDisabled;includeDeleted",
+		},
+		{name: "blank values", filter: &entities.AssetFilter{Tickers: []string{" "}}, want: "prices"},
+	}
+
+	for _, tt := range tests {
+		if got := CheckpointName("prices", tt.filter); got != tt.want {
+			t.Errorf("%s: CheckpointName() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// the same filter in another order pages with the same checkpoint
+	a := CheckpointName("prices", &entities.AssetFilter{Tickers: []string{"AAA", "BBB"}})
+	b := CheckpointName("prices", &entities.AssetFilter{Tickers: []string{"bbb", "aaa"}})
+	if a != b {
+		t.Errorf("CheckpointName() = %q and %q, want the same checkpoint", a, b)
+	}
+}
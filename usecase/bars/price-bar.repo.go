@@ -0,0 +1,27 @@
+package bars
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Price Bar Repository Interface
+///////////////////////////////////////////////////////////
+
+// Reader interface
+type Reader interface {
+}
+
+// Writer interface
+type Writer interface {
+	InsertPriceBars(ctx context.Context, bars []*entities.PriceBar) error
+	AdjustPriceBars(ctx context.Context, ticker string, before int64, fetchedBefore int64, factor float64) (int64, error)
+}
+
+// Repo interface
+type Repo interface {
+	Reader
+	Writer
+}
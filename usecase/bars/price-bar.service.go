@@ -0,0 +1,36 @@
+package bars
+
+import (
+	"context"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// Service sector
+type Service struct {
+	barRepo Repo
+	log     logger.ContextLog
+}
+
+// NewService create new service
+func NewService(barRepo Repo, log logger.ContextLog) *Service {
+	return &Service{
+		barRepo: barRepo,
+		log:     log,
+	}
+}
+
+// AddPriceBars stores daily price bars, bars already stored for the same day are replaced
+func (s *Service) AddPriceBars(ctx context.Context, bars []*entities.PriceBar) error {
+	s.log.Info(ctx, "adding price bars", "numBars", len(bars))
+	return s.barRepo.InsertPriceBars(ctx, bars)
+}
+
+// AdjustPriceBars applies an adjustment factor to the daily bars of a ticker dated before the
+// given date and fetched before fetchedBefore (unix seconds), bars fetched later already reflect
+// the adjustment. Returns the number of adjusted bars
+func (s *Service) AdjustPriceBars(ctx context.Context, ticker string, before int64, fetchedBefore int64, factor float64) (int64, error) {
+	s.log.Info(ctx, "adjusting price bars", "ticker", ticker, "before", before, "fetchedBefore", fetchedBefore, "factor", factor)
+	return s.barRepo.AdjustPriceBars(ctx, ticker, before, fetchedBefore, factor)
+}
@@ -0,0 +1,17 @@
+package bars
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Price Bar Source Interface
+///////////////////////////////////////////////////////////
+
+// BarSource interface
+type BarSource interface {
+	// FetchDailyBars fetches the daily bars of an asset between from and to (unix seconds)
+	FetchDailyBars(ctx context.Context, asset *entities.Asset, from int64, to int64) ([]*entities.PriceBar, error)
+}
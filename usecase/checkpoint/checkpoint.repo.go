@@ -12,11 +12,15 @@ import (
 
 // Reader interface
 type Reader interface {
+	FindCheckpoint(ctx context.Context, name string) (*entities.Checkpoint, error)
 }
 
 // Writer interface
 type Writer interface {
-	UpdateCheckpoint(ctx context.Context, pageSize int64, numAssets int64) (*entities.Checkpoint, error)
+	LeaseCheckpoint(ctx context.Context, name string, pageSize int64, numAssets int64, owner string, leaseMS uint64) (*entities.Checkpoint, error)
+	ReleaseCheckpoint(ctx context.Context, name string, owner string) error
+	ResetCheckpoint(ctx context.Context, name string) error
+	SetCheckpoint(ctx context.Context, name string, pageIndex int64) error
 }
 
 // Repo interface
@@ -2,7 +2,10 @@ package checkpoint
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
+	"github.com/google/uuid"
 	logger "github.com/lenoobz/aws-lambda-logger"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
 )
@@ -10,19 +13,123 @@ import (
 // Service sector
 type Service struct {
 	checkpointRepo Repo
+	owner          string
+	leaseMS        uint64
 	log            logger.ContextLog
 }
 
-// NewService create new service
-func NewService(checkpointRepo Repo, log logger.ContextLog) *Service {
+// NewService create new service. Each service is a run owning the page it leases for leaseMS
+func NewService(checkpointRepo Repo, leaseMS uint64, log logger.ContextLog) *Service {
+	id, _ := uuid.NewRandom()
+
 	return &Service{
 		checkpointRepo: checkpointRepo,
+		owner:          id.String(),
+		leaseMS:        leaseMS,
 		log:            log,
 	}
 }
 
-// GetAllAssets gets all assets
-func (s *Service) UpdateCheckpoint(ctx context.Context, pageSize int64, numAssets int64) (*entities.Checkpoint, error) {
-	s.log.Info(ctx, "updating checkpoint")
-	return s.checkpointRepo.UpdateCheckpoint(ctx, pageSize, numAssets)
+// LeaseCheckpoint leases the next page of assets of the named checkpoint to this run, the page of
+// a run whose lease expired comes first. Each job pages through the assets with its own checkpoint
+func (s *Service) LeaseCheckpoint(ctx context.Context, name string, pageSize int64, numAssets int64) (*entities.Checkpoint, error) {
+	s.log.Info(ctx, "leasing checkpoint", "name", name, "owner", s.owner)
+	return s.checkpointRepo.LeaseCheckpoint(ctx, name, pageSize, numAssets, s.owner, s.leaseMS)
+}
+
+// ReleaseCheckpoint releases the page of the named checkpoint leased to this run once it is processed
+func (s *Service) ReleaseCheckpoint(ctx context.Context, name string) error {
+	s.log.Info(ctx, "releasing checkpoint", "name", name, "owner", s.owner)
+	return s.checkpointRepo.ReleaseCheckpoint(ctx, name, s.owner)
+}
+
+// GetCheckpoint gets the named checkpoint, nil when no run has leased a page of it yet
+func (s *Service) GetCheckpoint(ctx context.Context, name string) (*entities.Checkpoint, error) {
+	s.log.Info(ctx, "getting checkpoint", "name", name)
+	return s.checkpointRepo.FindCheckpoint(ctx, name)
+}
+
+// ResetCheckpoint moves the named checkpoint back to the first page
+func (s *Service) ResetCheckpoint(ctx context.Context, name string) error {
+	s.log.Info(ctx, "resetting checkpoint", "name", name)
+	return s.checkpointRepo.ResetCheckpoint(ctx, name)
+}
+
+// SetCheckpoint moves the named checkpoint so the next run leases pageIndex
+func (s *Service) SetCheckpoint(ctx context.Context, name string, pageIndex int64) error {
+	s.log.Info(ctx, "setting checkpoint", "name", name, "index", pageIndex)
+
+	if pageIndex < 0 {
+		return fmt.Errorf("invalid page index %d", pageIndex)
+	}
+
+	return s.checkpointRepo.SetCheckpoint(ctx, name, pageIndex)
+}
+
+// GetCheckpointStatus gets how far the named checkpoint is through numAssets assets, and estimates
+// how long a full cycle through them takes from the times its pages were completed
+func (s *Service) GetCheckpointStatus(ctx context.Context, name string, numAssets int64) (*entities.CheckpointStatus, error) {
+	checkpoint, err := s.GetCheckpoint(ctx, name)
+	if err != nil {
+		s.log.Error(ctx, "get checkpoint failed", "error", err, "name", name)
+		return nil, err
+	}
+
+	status := &entities.CheckpointStatus{
+		Name:       name,
+		Checkpoint: checkpoint,
+		NumAssets:  numAssets,
+	}
+
+	if checkpoint == nil || checkpoint.PageSize <= 0 {
+		return status, nil
+	}
+
+	pageSize := checkpoint.PageSize
+	status.NumPages = (numAssets + pageSize - 1) / pageSize
+
+	// the same wrap around as the checkpoint advance
+	status.NextPageIndex = checkpoint.PageIndex + 1
+	if status.NextPageIndex*pageSize >= numAssets {
+		status.NextPageIndex = 0
+	}
+
+	status.ProcessedAssets = (checkpoint.PageIndex + 1) * pageSize
+	if status.ProcessedAssets > numAssets {
+		status.ProcessedAssets = numAssets
+	}
+
+	if numAssets > 0 {
+		status.Progress = float64(status.ProcessedAssets) / float64(numAssets) * 100
+	}
+
+	if interval := pageInterval(checkpoint.CompletedPages); interval > 0 {
+		status.PageInterval = interval
+		status.CycleDuration = interval * status.NumPages
+		status.RemainingDuration = interval * (status.NumPages - checkpoint.PageIndex - 1)
+		if status.RemainingDuration < 0 {
+			status.RemainingDuration = 0
+		}
+	}
+
+	return status, nil
+}
+
+// pageInterval returns the average number of seconds between two completed pages, zero when less
+// than two pages are completed
+func pageInterval(pages []*entities.CheckpointPage) int64 {
+	if len(pages) < 2 {
+		return 0
+	}
+
+	var times []int64
+	for _, page := range pages {
+		times = append(times, page.CompletedAt)
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+
+	return (times[len(times)-1] - times[0]) / int64(len(times)-1)
 }
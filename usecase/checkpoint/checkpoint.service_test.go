@@ -0,0 +1,153 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// fakeCheckpointRepo holds a single checkpoint
+type fakeCheckpointRepo struct {
+	Repo
+	checkpoint *entities.Checkpoint
+}
+
+func (r *fakeCheckpointRepo) FindCheckpoint(ctx context.Context, name string) (*entities.Checkpoint, error) {
+	return r.checkpoint, nil
+}
+
+// completedPages returns pages completed at the given times
+func completedPages(times ...int64) []*entities.CheckpointPage {
+	var pages []*entities.CheckpointPage
+	for i, completedAt := range times {
+		pages = append(pages, &entities.CheckpointPage{PageIndex: int64(i), CompletedAt: completedAt})
+	}
+
+	return pages
+}
+
+func TestPageInterval(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []*entities.CheckpointPage
+		want  int64
+	}{
+		{name: "no pages", want: 0},
+		{name: "single page", pages: completedPages(100), want: 0},
+		{name: "two pages", pages: completedPages(100, 400), want: 300},
+		{name: "unordered pages", pages: completedPages(400, 100, 250), want: 150},
+		{name: "same time", pages: completedPages(100, 100), want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := pageInterval(tt.pages); got != tt.want {
+			t.Errorf("%s: pageInterval() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetCheckpointStatus(t *testing.T) {
+	zap, err := logger.NewZapLogger()
+	if err != nil {
+		t.Fatalf("NewZapLogger() error = %v", err)
+	}
+	defer zap.Close()
+
+	// 25 assets are 3 pages of 10, one page every 150 seconds
+	pages := completedPages(100, 400, 250)
+
+	tests := []struct {
+		name       string
+		checkpoint *entities.Checkpoint
+		numAssets  int64
+		want       entities.CheckpointStatus
+	}{
+		{
+			name:      "no checkpoint",
+			numAssets: 25,
+			want:      entities.CheckpointStatus{NumAssets: 25},
+		},
+		{
+			name:       "first page",
+			checkpoint: &entities.Checkpoint{PageSize: 10, PageIndex: 0, CompletedPages: pages},
+			numAssets:  25,
+			want: entities.CheckpointStatus{
+				NumAssets:         25,
+				NumPages:          3,
+				NextPageIndex:     1,
+				ProcessedAssets:   10,
+				Progress:          40,
+				PageInterval:      150,
+				CycleDuration:     450,
+				RemainingDuration: 300,
+			},
+		},
+		{
+			name:       "last page wraps",
+			checkpoint: &entities.Checkpoint{PageSize: 10, PageIndex: 2, CompletedPages: pages},
+			numAssets:  25,
+			want: entities.CheckpointStatus{
+				NumAssets:       25,
+				NumPages:        3,
+				NextPageIndex:   0,
+				ProcessedAssets: 25,
+				Progress:        100,
+				PageInterval:    150,
+				CycleDuration:   450,
+			},
+		},
+		{
+			name:       "page past the assets",
+			checkpoint: &entities.Checkpoint{PageSize: 10, PageIndex: 5, CompletedPages: pages},
+			numAssets:  25,
+			want: entities.CheckpointStatus{
+				NumAssets:       25,
+				NumPages:        3,
+				NextPageIndex:   0,
+				ProcessedAssets: 25,
+				Progress:        100,
+				PageInterval:    150,
+				CycleDuration:   450,
+			},
+		},
+		{
+			name:       "reset",
+			checkpoint: &entities.Checkpoint{PageSize: 10, PageIndex: -1, CompletedPages: pages},
+			numAssets:  25,
+			want: entities.CheckpointStatus{
+				NumAssets:         25,
+				NumPages:          3,
+				NextPageIndex:     0,
+				ProcessedAssets:   0,
+				Progress:          0,
+				PageInterval:      150,
+				CycleDuration:     450,
+				RemainingDuration: 450,
+			},
+		},
+		{
+			name:       "no assets",
+			checkpoint: &entities.Checkpoint{PageSize: 10, PageIndex: 0},
+			numAssets:  0,
+			want:       entities.CheckpointStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		service := NewService(&fakeCheckpointRepo{checkpoint: tt.checkpoint}, 60000, zap)
+
+		got, err := service.GetCheckpointStatus(context.Background(), "prices", tt.numAssets)
+		if err != nil {
+			t.Fatalf("%s: GetCheckpointStatus() error = %v", tt.name, err)
+		}
+
+		want := tt.want
+		want.Name = "prices"
+		want.Checkpoint = tt.checkpoint
+		if *got != want {
+			t.Errorf("%s: GetCheckpointStatus() = %+v\nwant %+v", tt.name, *got, want)
+		}
+	}
+}
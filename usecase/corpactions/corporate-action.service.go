@@ -0,0 +1,262 @@
+package corpactions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/bars"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+const (
+	// splitMatchWindow is how far apart two split events of a ticker can be and still be the same split
+	splitMatchWindow = 3 * 24 * time.Hour
+	// priceTolerance is the relative error allowed between a price jump and a split ratio
+	priceTolerance = 0.05
+	// volumeTolerance is the multiple of the split ratio the volume jump must stay within
+	volumeTolerance = 2.0
+)
+
+// splitRatios are the split ratios (numerator:denominator) a price jump is matched against
+var splitRatios = [][2]float64{
+	{3, 2}, {2, 1}, {3, 1}, {4, 1}, {5, 1}, {8, 1}, {10, 1}, {20, 1},
+	{2, 3}, {1, 2}, {1, 3}, {1, 4}, {1, 5}, {1, 8}, {1, 10}, {1, 20},
+}
+
+// Service sector
+type Service struct {
+	priceService *price.Service
+	eventService *events.Service
+	barService   *bars.Service
+	log          logger.ContextLog
+}
+
+// NewService create new service
+func NewService(priceService *price.Service, eventService *events.Service, barService *bars.Service, log logger.ContextLog) *Service {
+	return &Service{
+		priceService: priceService,
+		eventService: eventService,
+		barService:   barService,
+		log:          log,
+	}
+}
+
+// DetectSplits looks for price jumps matching a split ratio with a matching volume jump in the
+// price history of a ticker between from and to, and stores those not already known as split events
+func (s *Service) DetectSplits(ctx context.Context, ticker string, from time.Time, to time.Time) ([]*entities.AssetEvent, error) {
+	history, err := s.priceService.GetAssetPriceHistory(ctx, ticker, from.Unix(), to.Unix())
+	if err != nil {
+		s.log.Error(ctx, "get asset price history failed", "error", err, "ticker", ticker)
+		return nil, err
+	}
+
+	detected := DetectRatioJumps(ticker, history)
+	if len(detected) == 0 {
+		return nil, nil
+	}
+
+	known, err := s.eventService.GetAssetEvents(ctx, ticker, consts.SPLIT_EVENT, from.Add(-splitMatchWindow).Unix(), to.Add(splitMatchWindow).Unix())
+	if err != nil {
+		s.log.Error(ctx, "get split events failed", "error", err, "ticker", ticker)
+		return nil, err
+	}
+
+	var splits []*entities.AssetEvent
+	for _, split := range detected {
+		if findMatchingSplit(known, split.Date) != nil {
+			continue
+		}
+
+		s.log.Info(ctx, "detected split from price jump", "ticker", ticker, "date", split.Date, "splitRatio", split.SplitRatio)
+		splits = append(splits, split)
+	}
+
+	if err := s.eventService.AddAssetEvents(ctx, splits); err != nil {
+		s.log.Error(ctx, "add split events failed", "error", err, "ticker", ticker)
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+// ApplySplits adjusts the price history and daily bars of a ticker for every split not applied yet, oldest first.
+// A split close to one already applied is the same split reported by another source, it is marked
+// applied with a factor of one. Returns the number of applied splits
+func (s *Service) ApplySplits(ctx context.Context, ticker string) (int, error) {
+	now := time.Now().UTC()
+
+	pending, err := s.eventService.GetPendingSplits(ctx, ticker, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	splits, err := s.eventService.GetAssetEvents(ctx, ticker, consts.SPLIT_EVENT, 0, now.Unix())
+	if err != nil {
+		s.log.Error(ctx, "get split events failed", "error", err, "ticker", ticker)
+		return 0, err
+	}
+
+	var applied []*entities.AssetEvent
+	for _, split := range splits {
+		if split.AppliedAt != 0 {
+			applied = append(applied, split)
+		}
+	}
+
+	// the exchange timezone tells when the trading day of the split starts
+	loc := time.UTC
+	if assetPrice, err := s.priceService.GetAssetPrice(ctx, ticker); err == nil && assetPrice != nil && assetPrice.ExchangeTimezone != "" {
+		if exchangeLoc, err := time.LoadLocation(assetPrice.ExchangeTimezone); err == nil {
+			loc = exchangeLoc
+		}
+	}
+
+	numApplied := 0
+	for _, split := range pending {
+		if match := findMatchingSplit(applied, split.Date); match != nil {
+			s.log.Info(ctx, "split already applied", "ticker", ticker, "date", split.Date, "appliedDate", match.Date)
+			if err := s.eventService.MarkAssetEventApplied(ctx, split, 1, now); err != nil {
+				return numApplied, err
+			}
+			continue
+		}
+
+		factor := split.SplitFactor()
+		if factor == 0 {
+			s.log.Error(ctx, "invalid split ratio", "ticker", ticker, "date", split.Date, "splitRatio", split.SplitRatio)
+			continue
+		}
+
+		numAdjusted, err := s.priceService.AdjustAssetPriceHistory(ctx, ticker, tradingDayStart(split.Date, loc), factor)
+		if err != nil {
+			s.log.Error(ctx, "adjust price history failed", "error", err, "ticker", ticker, "date", split.Date)
+			return numApplied, err
+		}
+
+		// bars are dated like the split, midnight UTC of the exchange's local day. Bars fetched
+		// from the start of the split day on are adjusted by the chart already
+		numBars, err := s.barService.AdjustPriceBars(ctx, ticker, split.Date, tradingDayStart(split.Date, loc), factor)
+		if err != nil {
+			s.log.Error(ctx, "adjust price bars failed", "error", err, "ticker", ticker, "date", split.Date)
+			return numApplied, err
+		}
+
+		s.log.Info(ctx, "applied split", "ticker", ticker, "date", split.Date, "factor", factor, "numAdjusted", numAdjusted, "numBars", numBars)
+		if err := s.eventService.MarkAssetEventApplied(ctx, split, factor, now); err != nil {
+			return numApplied, err
+		}
+		applied = append(applied, split)
+		numApplied++
+	}
+
+	return numApplied, nil
+}
+
+// DetectRatioJumps compares the last observation of consecutive trading days of a price history
+// sorted by observed time, and returns a split event for each day the adjusted price jumped by a
+// split ratio while the volume moved the opposite way by about the same ratio
+func DetectRatioJumps(ticker string, history []*entities.AssetPrice) []*entities.AssetEvent {
+	closes := dailyCloses(history)
+
+	var splits []*entities.AssetEvent
+	for i := 1; i < len(closes); i++ {
+		prev, cur := closes[i-1], closes[i]
+		if prev.price.AdjPrice <= 0 || cur.price.AdjPrice <= 0 || prev.price.Volume <= 0 || cur.price.Volume <= 0 {
+			continue
+		}
+
+		priceRatio := prev.price.AdjPrice / cur.price.AdjPrice
+		volumeRatio := float64(cur.price.Volume) / float64(prev.price.Volume)
+
+		for _, ratio := range splitRatios {
+			splitRatio := ratio[0] / ratio[1]
+			if math.Abs(priceRatio/splitRatio-1) > priceTolerance {
+				continue
+			}
+
+			if volumeRatio/splitRatio >= volumeTolerance || splitRatio/volumeRatio >= volumeTolerance {
+				break
+			}
+
+			splits = append(splits, &entities.AssetEvent{
+				Ticker:      ticker,
+				Type:        consts.SPLIT_EVENT,
+				Date:        cur.date,
+				Numerator:   ratio[0],
+				Denominator: ratio[1],
+				SplitRatio:  fmt.Sprintf("%g:%g", ratio[0], ratio[1]),
+				Source:      consts.RATIO_JUMP_SOURCE,
+			})
+			break
+		}
+	}
+
+	return splits
+}
+
+// dailyClose is the last observation of a trading day
+type dailyClose struct {
+	date  int64
+	price *entities.AssetPrice
+}
+
+// dailyCloses reduces a price history sorted by observed time to its last observation per trading
+// day. Days are midnight UTC of the exchange's local day, like the dates of the chart events
+func dailyCloses(history []*entities.AssetPrice) []*dailyClose {
+	var closes []*dailyClose
+	for _, assetPrice := range history {
+		quotedAt := assetPrice.MarketTime
+		if quotedAt == 0 {
+			quotedAt = assetPrice.ObservedAt
+		}
+
+		loc := time.UTC
+		if assetPrice.ExchangeTimezone != "" {
+			if exchangeLoc, err := time.LoadLocation(assetPrice.ExchangeTimezone); err == nil {
+				loc = exchangeLoc
+			}
+		}
+
+		y, m, d := time.Unix(quotedAt, 0).In(loc).Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()
+
+		if n := len(closes); n > 0 && closes[n-1].date >= date {
+			if closes[n-1].date == date {
+				closes[n-1].price = assetPrice
+			}
+			continue
+		}
+
+		closes = append(closes, &dailyClose{date: date, price: assetPrice})
+	}
+
+	return closes
+}
+
+// findMatchingSplit returns the split within the match window of date, nil when there is none
+func findMatchingSplit(splits []*entities.AssetEvent, date int64) *entities.AssetEvent {
+	window := int64(splitMatchWindow / time.Second)
+	for _, split := range splits {
+		if split.Date >= date-window && split.Date <= date+window {
+			return split
+		}
+	}
+
+	return nil
+}
+
+// tradingDayStart returns the instant (unix seconds) the exchange's local day of an event date starts
+func tradingDayStart(date int64, loc *time.Location) int64 {
+	y, m, d := time.Unix(date, 0).UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc).Unix()
+}
@@ -0,0 +1,170 @@
+package corpactions
+
+import (
+	"context"
+	"testing"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/bars"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/events"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+const day = int64(24 * 60 * 60)
+
+func TestFindMatchingSplit(t *testing.T) {
+	// 2023-09-20 and 2024-01-10 UTC
+	first := &entities.AssetEvent{Date: 1695168000}
+	second := &entities.AssetEvent{Date: 1704844800}
+	splits := []*entities.AssetEvent{first, second}
+
+	tests := []struct {
+		name string
+		date int64
+		want *entities.AssetEvent
+	}{
+		{name: "same day", date: first.Date, want: first},
+		{name: "days before", date: first.Date - 2*day, want: first},
+		{name: "window edge", date: first.Date + 3*day, want: first},
+		{name: "past window", date: first.Date + 3*day + 1, want: nil},
+		{name: "second split", date: second.Date - day, want: second},
+		{name: "between splits", date: first.Date + 30*day, want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := findMatchingSplit(splits, tt.date); got != tt.want {
+			t.Errorf("%s: findMatchingSplit(%d) = %v, want %v", tt.name, tt.date, got, tt.want)
+		}
+	}
+
+	if got := findMatchingSplit(nil, first.Date); got != nil {
+		t.Errorf("findMatchingSplit() of no splits = %v, want nil", got)
+	}
+}
+
+func TestDetectRatioJumps(t *testing.T) {
+	// closes of 2023-09-18 to 2023-09-20 UTC, with a 2:1 split on the 20th
+	history := []*entities.AssetPrice{
+		{MarketTime: 1695056400, AdjPrice: 100, Volume: 1000},
+		{MarketTime: 1695142800, AdjPrice: 102, Volume: 1100},
+		{MarketTime: 1695229200, AdjPrice: 51.5, Volume: 2300},
+	}
+
+	splits := DetectRatioJumps("AAA", history)
+	if len(splits) != 1 {
+		t.Fatalf("DetectRatioJumps() = %d splits, want 1", len(splits))
+	}
+
+	if got := splits[0]; got.SplitRatio != "2:1" || got.Date != 1695168000 || got.Ticker != "AAA" {
+		t.Errorf("DetectRatioJumps() = %+v, want a 2:1 split of AAA on 1695168000", got)
+	}
+
+	// a price halving without the volume doubling is not a split
+	history[2].Volume = 1100
+	if splits := DetectRatioJumps("AAA", history); len(splits) != 0 {
+		t.Errorf("DetectRatioJumps() without a volume jump = %v, want none", splits)
+	}
+}
+
+// fakeEventRepo holds the split events of a ticker
+type fakeEventRepo struct {
+	events.Repo
+	splits []*entities.AssetEvent
+}
+
+func (r *fakeEventRepo) FindAssetEvents(ctx context.Context, ticker string, eventType string, from int64, to int64) ([]*entities.AssetEvent, error) {
+	return r.splits, nil
+}
+
+func (r *fakeEventRepo) UpdateAssetEventApplied(ctx context.Context, event *entities.AssetEvent) error {
+	return nil
+}
+
+// fakePriceRepo holds no prices
+type fakePriceRepo struct {
+	price.Repo
+}
+
+func (r *fakePriceRepo) FindAssetPrice(ctx context.Context, ticker string) (*entities.AssetPrice, error) {
+	return nil, nil
+}
+
+func (r *fakePriceRepo) AdjustAssetPriceHistory(ctx context.Context, ticker string, before int64, factor float64) (int64, error) {
+	return 0, nil
+}
+
+// fakeBarRepo holds the bars of a ticker by date and adjusts them like the mongo repo
+type fakeBarRepo struct {
+	bars.Repo
+	bars map[int64]*entities.PriceBar
+}
+
+func (r *fakeBarRepo) InsertPriceBars(ctx context.Context, priceBars []*entities.PriceBar) error {
+	for _, bar := range priceBars {
+		stored := *bar
+		stored.AdjFactor = 1
+		r.bars[bar.Date] = &stored
+	}
+	return nil
+}
+
+func (r *fakeBarRepo) AdjustPriceBars(ctx context.Context, ticker string, before int64, fetchedBefore int64, factor float64) (int64, error) {
+	var numAdjusted int64
+	for _, bar := range r.bars {
+		if bar.Date < before && (bar.FetchedAt == 0 || bar.FetchedAt < fetchedBefore) {
+			bar.AdjFactor *= factor
+			numAdjusted++
+		}
+	}
+	return numAdjusted, nil
+}
+
+func TestApplySplitsSkipsBarsBackfilledAfterTheSplit(t *testing.T) {
+	zap, err := logger.NewZapLogger()
+	if err != nil {
+		t.Fatalf("NewZapLogger() error = %v", err)
+	}
+	defer zap.Close()
+
+	// a 2:1 split on 2023-09-20 UTC
+	split := &entities.AssetEvent{Ticker: "AAA", Date: 1695168000, Numerator: 2, Denominator: 1}
+
+	barRepo := &fakeBarRepo{bars: map[int64]*entities.PriceBar{}}
+	barService := bars.NewService(barRepo, zap)
+	service := NewService(price.NewService(&fakePriceRepo{}, zap), events.NewService(&fakeEventRepo{splits: []*entities.AssetEvent{split}}, zap), barService, zap)
+
+	ctx := context.Background()
+
+	// the day before the split was fetched before it, then backfilled again after it
+	if err := barService.AddPriceBars(ctx, []*entities.PriceBar{
+		{Ticker: "AAA", Date: split.Date - 2*day, Close: 200, FetchedAt: split.Date - day},
+		{Ticker: "AAA", Date: split.Date - day, Close: 200, FetchedAt: split.Date - day},
+	}); err != nil {
+		t.Fatalf("AddPriceBars() error = %v", err)
+	}
+
+	if err := barService.AddPriceBars(ctx, []*entities.PriceBar{
+		{Ticker: "AAA", Date: split.Date - day, Close: 100, FetchedAt: split.Date + day},
+		{Ticker: "AAA", Date: split.Date, Close: 101, FetchedAt: split.Date + day},
+	}); err != nil {
+		t.Fatalf("AddPriceBars() error = %v", err)
+	}
+
+	numApplied, err := service.ApplySplits(ctx, "AAA")
+	if err != nil || numApplied != 1 {
+		t.Fatalf("ApplySplits() = %d, %v, want 1 split applied", numApplied, err)
+	}
+
+	want := map[int64]float64{
+		split.Date - 2*day: 0.5,
+		split.Date - day:   1,
+		split.Date:         1,
+	}
+
+	for date, adjFactor := range want {
+		if got := barRepo.bars[date].AdjFactor; got != adjFactor {
+			t.Errorf("adjustment factor of the bar of %d = %v, want %v", date, got, adjFactor)
+		}
+	}
+}
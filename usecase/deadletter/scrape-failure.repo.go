@@ -0,0 +1,27 @@
+package deadletter
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Scrape Failure Repository Interface
+///////////////////////////////////////////////////////////
+
+// Reader interface
+type Reader interface {
+}
+
+// Writer interface
+type Writer interface {
+	UpsertScrapeFailure(ctx context.Context, failure *entities.ScrapeFailure) (*entities.ScrapeFailure, error)
+	DeleteScrapeFailures(ctx context.Context, tickers []string) error
+}
+
+// Repo interface
+type Repo interface {
+	Reader
+	Writer
+}
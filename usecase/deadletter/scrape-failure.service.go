@@ -0,0 +1,121 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/price"
+)
+
+// Service sector
+type Service struct {
+	failureRepo  Repo
+	assetService *assets.Service
+	// quarantineThreshold is the number of consecutive failed runs after which a ticker is
+	// quarantined, zero never quarantines
+	quarantineThreshold int64
+	log                 logger.ContextLog
+}
+
+// NewService create new service
+func NewService(failureRepo Repo, assetService *assets.Service, quarantineThreshold int64, log logger.ContextLog) *Service {
+	return &Service{
+		failureRepo:         failureRepo,
+		assetService:        assetService,
+		quarantineThreshold: quarantineThreshold,
+		log:                 log,
+	}
+}
+
+// RecordFailures records the failure of each ticker of a run, flags the tickers yahoo no longer
+// knows as suspected delisted and quarantines the tickers that failed too many runs in a row,
+// returns the quarantined tickers. Store errors are not the ticker's fault and are not recorded
+func (s *Service) RecordFailures(ctx context.Context, failures map[string]error) ([]string, error) {
+	s.log.Info(ctx, "recording scrape failures", "numFailures", len(failures))
+
+	now := time.Now().UTC().Unix()
+
+	var quarantined []string
+	for ticker, fetchErr := range failures {
+		failure := NewScrapeFailure(ticker, fetchErr, now)
+		if failure.ErrorClass == consts.STORE_ERROR {
+			continue
+		}
+
+		stored, err := s.failureRepo.UpsertScrapeFailure(ctx, failure)
+		if err != nil {
+			s.log.Error(ctx, "upsert scrape failure failed", "error", err, "ticker", ticker)
+			return quarantined, err
+		}
+
+		var priceErr *price.FetchError
+		if errors.As(fetchErr, &priceErr) && priceErr.IsDelisted() {
+			if err := s.assetService.FlagAssetDelisted(ctx, ticker, priceErr.Successor); err != nil {
+				s.log.Error(ctx, "flag asset delisted failed", "error", err, "ticker", ticker)
+				return quarantined, err
+			}
+		}
+
+		if s.quarantineThreshold <= 0 || stored.ConsecutiveFailures < s.quarantineThreshold {
+			continue
+		}
+
+		reason := fmt.Sprintf("%d consecutive %s failures: %s", stored.ConsecutiveFailures, stored.ErrorClass, stored.LastError)
+		if err := s.assetService.QuarantineAsset(ctx, ticker, reason); err != nil {
+			s.log.Error(ctx, "quarantine asset failed", "error", err, "ticker", ticker)
+			return quarantined, err
+		}
+
+		quarantined = append(quarantined, ticker)
+	}
+
+	return quarantined, nil
+}
+
+// ClearFailures resets the consecutive failures of the given tickers and clears their suspected
+// delisted flag, yahoo knows them again
+func (s *Service) ClearFailures(ctx context.Context, tickers []string) error {
+	s.log.Info(ctx, "clearing scrape failures", "numTickers", len(tickers))
+
+	if err := s.failureRepo.DeleteScrapeFailures(ctx, tickers); err != nil {
+		return err
+	}
+
+	return s.assetService.ClearAssetDelisted(ctx, tickers)
+}
+
+// ClearQuarantine lifts the quarantine of the given tickers and resets their consecutive failures
+func (s *Service) ClearQuarantine(ctx context.Context, tickers []string) (int64, error) {
+	numCleared, err := s.assetService.ClearAssetQuarantine(ctx, tickers)
+	if err != nil {
+		s.log.Error(ctx, "clear asset quarantine failed", "error", err)
+		return 0, err
+	}
+
+	return numCleared, s.ClearFailures(ctx, tickers)
+}
+
+// NewScrapeFailure creates the failure record of a ticker from the error a source reported
+func NewScrapeFailure(ticker string, err error, failedAt int64) *entities.ScrapeFailure {
+	failure := &entities.ScrapeFailure{
+		Ticker:       ticker,
+		LastError:    err.Error(),
+		LastFailedAt: failedAt,
+	}
+
+	var fetchErr *price.FetchError
+	if errors.As(err, &fetchErr) {
+		failure.ErrorClass = fetchErr.Class
+		failure.HTTPStatus = fetchErr.Status
+		failure.SnippetHash = fetchErr.SnippetHash
+		failure.Successor = fetchErr.Successor
+	}
+
+	return failure
+}
@@ -0,0 +1,18 @@
+package enrichment
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Asset Metadata Source Interface
+///////////////////////////////////////////////////////////
+
+// MetadataSource interface
+type MetadataSource interface {
+	// FetchAssetMetadata fetches the metadata of a batch of assets. Assets whose metadata could not
+	// be fetched are reported in the returned map keyed by ticker
+	FetchAssetMetadata(ctx context.Context, assets []*entities.Asset) ([]*entities.AssetMetadata, map[string]error)
+}
@@ -0,0 +1,90 @@
+package enrichment
+
+import (
+	"context"
+	"strings"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+)
+
+// Service sector
+type Service struct {
+	metadataSource MetadataSource
+	assetService   *assets.Service
+	log            logger.ContextLog
+}
+
+// NewService create new service
+func NewService(metadataSource MetadataSource, assetService *assets.Service, log logger.ContextLog) *Service {
+	return &Service{
+		metadataSource: metadataSource,
+		assetService:   assetService,
+		log:            log,
+	}
+}
+
+// EnrichAssets fetches the metadata of the given assets, fills their empty name, type, currency and
+// exchange, and flags the assets whose currency differs from the one the source reports. Returns
+// the tickers with a currency mismatch and the tickers that could not be enriched
+func (s *Service) EnrichAssets(ctx context.Context, assetList []*entities.Asset) ([]string, map[string]error) {
+	metadata, failures := s.metadataSource.FetchAssetMetadata(ctx, assetList)
+
+	assetsByTicker := map[string]*entities.Asset{}
+	for _, asset := range assetList {
+		assetsByTicker[asset.Ticker] = asset
+	}
+
+	var mismatches []string
+	for _, reported := range metadata {
+		asset, ok := assetsByTicker[reported.Ticker]
+		if !ok {
+			continue
+		}
+
+		missing := missingMetadata(asset, reported)
+
+		reportedCurrency := ""
+		if asset.Currency != "" && reported.Currency != "" && !strings.EqualFold(asset.Currency, reported.Currency) {
+			s.log.Error(ctx, "currency mismatch", "ticker", asset.Ticker, "currency", asset.Currency, "reportedCurrency", reported.Currency)
+			reportedCurrency = reported.Currency
+			mismatches = append(mismatches, asset.Ticker)
+		}
+
+		// nothing to fill and no flag to set or clear
+		if *missing == (entities.AssetMetadata{}) && reportedCurrency == "" && !asset.CurrencyMismatch {
+			continue
+		}
+
+		if err := s.assetService.UpdateAssetMetadata(ctx, asset.Ticker, missing, reportedCurrency); err != nil {
+			s.log.Error(ctx, "update asset metadata failed", "error", err, "ticker", asset.Ticker)
+			failures[asset.Ticker] = err
+		}
+	}
+
+	return mismatches, failures
+}
+
+// missingMetadata returns the reported metadata of the fields the asset does not have yet
+func missingMetadata(asset *entities.Asset, reported *entities.AssetMetadata) *entities.AssetMetadata {
+	missing := &entities.AssetMetadata{}
+
+	if asset.Name == "" {
+		missing.Name = reported.Name
+	}
+
+	if asset.Type == "" {
+		missing.Type = reported.Type
+	}
+
+	if asset.Currency == "" {
+		missing.Currency = reported.Currency
+	}
+
+	if asset.Exchange == "" {
+		missing.Exchange = reported.Exchange
+	}
+
+	return missing
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Asset Event Repository Interface
+///////////////////////////////////////////////////////////
+
+// Reader interface
+type Reader interface {
+	FindAssetEvents(ctx context.Context, ticker string, eventType string, from int64, to int64) ([]*entities.AssetEvent, error)
+}
+
+// Writer interface
+type Writer interface {
+	InsertAssetEvents(ctx context.Context, events []*entities.AssetEvent) error
+	UpdateAssetEventApplied(ctx context.Context, event *entities.AssetEvent) error
+}
+
+// Repo interface
+type Repo interface {
+	Reader
+	Writer
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// trailingYear is the window of the trailing 12 month figures
+const trailingYear = 365 * 24 * time.Hour
+
+// Service sector
+type Service struct {
+	eventRepo Repo
+	log       logger.ContextLog
+}
+
+// NewService create new service
+func NewService(eventRepo Repo, log logger.ContextLog) *Service {
+	return &Service{
+		eventRepo: eventRepo,
+		log:       log,
+	}
+}
+
+// AddAssetEvents stores asset events, events already stored for the same day are replaced
+func (s *Service) AddAssetEvents(ctx context.Context, events []*entities.AssetEvent) error {
+	s.log.Info(ctx, "adding asset events", "numEvents", len(events))
+	return s.eventRepo.InsertAssetEvents(ctx, events)
+}
+
+// GetAssetEvents gets events of a type for a ticker between from and to (unix seconds, inclusive)
+func (s *Service) GetAssetEvents(ctx context.Context, ticker string, eventType string, from int64, to int64) ([]*entities.AssetEvent, error) {
+	s.log.Info(ctx, "getting asset events", "ticker", ticker, "type", eventType)
+	return s.eventRepo.FindAssetEvents(ctx, ticker, eventType, from, to)
+}
+
+// GetPendingSplits gets the split events of a ticker not yet applied to its price history, oldest first
+func (s *Service) GetPendingSplits(ctx context.Context, ticker string, now time.Time) ([]*entities.AssetEvent, error) {
+	splits, err := s.eventRepo.FindAssetEvents(ctx, ticker, consts.SPLIT_EVENT, 0, now.Unix())
+	if err != nil {
+		s.log.Error(ctx, "find splits failed", "error", err, "ticker", ticker)
+		return nil, err
+	}
+
+	var pending []*entities.AssetEvent
+	for _, split := range splits {
+		if split.AppliedAt == 0 {
+			pending = append(pending, split)
+		}
+	}
+
+	return pending, nil
+}
+
+// MarkAssetEventApplied records that an event was applied with the given adjustment factor
+func (s *Service) MarkAssetEventApplied(ctx context.Context, event *entities.AssetEvent, factor float64, now time.Time) error {
+	s.log.Info(ctx, "marking asset event applied", "ticker", event.Ticker, "type", event.Type, "date", event.Date, "factor", factor)
+
+	event.AdjFactor = factor
+	event.AppliedAt = now.Unix()
+
+	return s.eventRepo.UpdateAssetEventApplied(ctx, event)
+}
+
+// GetTrailingDistribution computes the distribution figures of a ticker from its dividends
+// of the trailing 12 months, against the given price
+func (s *Service) GetTrailingDistribution(ctx context.Context, ticker string, price float64, now time.Time) (*entities.AssetDistribution, error) {
+	dividends, err := s.eventRepo.FindAssetEvents(ctx, ticker, consts.DIVIDEND_EVENT, now.Add(-trailingYear).Unix(), now.Unix())
+	if err != nil {
+		s.log.Error(ctx, "find dividends failed", "error", err, "ticker", ticker)
+		return nil, err
+	}
+
+	return ComputeDistribution(dividends, price), nil
+}
+
+// ComputeDistribution computes the distribution figures from a year of dividends sorted by date.
+// The schedule is inferred from the number of payments, the distribution yield annualizes the
+// latest payment and the 12 month yield sums all of them. Yields are percentages of price
+func ComputeDistribution(dividends []*entities.AssetEvent, price float64) *entities.AssetDistribution {
+	if len(dividends) == 0 || price <= 0 {
+		return &entities.AssetDistribution{}
+	}
+
+	total := 0.0
+	for _, dividend := range dividends {
+		total += dividend.Amount
+	}
+
+	schedule, paymentsPerYear := dividendSchedule(len(dividends))
+	latest := dividends[len(dividends)-1].Amount
+
+	return &entities.AssetDistribution{
+		DividendSchedule: schedule,
+		Yield12Month:     total / price * 100,
+		DistYield:        latest * paymentsPerYear / price * 100,
+		DistAmount:       latest,
+	}
+}
+
+// dividendSchedule infers the dividend schedule from the number of payments in a year
+func dividendSchedule(numPayments int) (string, float64) {
+	switch {
+	case numPayments >= 10:
+		return consts.MONTHLY_SCHEDULE, 12
+	case numPayments >= 3:
+		return consts.QUARTERLY_SCHEDULE, 4
+	case numPayments == 2:
+		return consts.SEMI_ANNUALLY_SCHEDULE, 2
+	default:
+		return consts.ANNUALLY_SCHEDULE, 1
+	}
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// dividendsOf returns one dividend per amount, oldest first
+func dividendsOf(amounts ...float64) []*entities.AssetEvent {
+	var dividends []*entities.AssetEvent
+	for i, amount := range amounts {
+		dividends = append(dividends, &entities.AssetEvent{Amount: amount, Date: int64(i)})
+	}
+
+	return dividends
+}
+
+func TestComputeDistribution(t *testing.T) {
+	monthly := make([]float64, 12)
+	for i := range monthly {
+		monthly[i] = 0.1
+	}
+
+	tests := []struct {
+		name      string
+		dividends []*entities.AssetEvent
+		price     float64
+		want      entities.AssetDistribution
+	}{
+		{
+			name:      "monthly",
+			dividends: dividendsOf(monthly...),
+			price:     24,
+			want:      entities.AssetDistribution{DividendSchedule: consts.MONTHLY_SCHEDULE, Yield12Month: 5, DistYield: 5, DistAmount: 0.1},
+		},
+		{
+			// the latest payment is annualized while the 12 month yield sums what was paid
+			name:      "quarterly raise",
+			dividends: dividendsOf(0.5, 0.5, 0.5, 1),
+			price:     100,
+			want:      entities.AssetDistribution{DividendSchedule: consts.QUARTERLY_SCHEDULE, Yield12Month: 2.5, DistYield: 4, DistAmount: 1},
+		},
+		{
+			name:      "semi annually",
+			dividends: dividendsOf(1, 1),
+			price:     50,
+			want:      entities.AssetDistribution{DividendSchedule: consts.SEMI_ANNUALLY_SCHEDULE, Yield12Month: 4, DistYield: 4, DistAmount: 1},
+		},
+		{
+			name:      "annually",
+			dividends: dividendsOf(3),
+			price:     60,
+			want:      entities.AssetDistribution{DividendSchedule: consts.ANNUALLY_SCHEDULE, Yield12Month: 5, DistYield: 5, DistAmount: 3},
+		},
+		{
+			name:      "no dividends",
+			dividends: nil,
+			price:     60,
+			want:      entities.AssetDistribution{},
+		},
+		{
+			name:      "no price",
+			dividends: dividendsOf(1),
+			price:     0,
+			want:      entities.AssetDistribution{},
+		},
+	}
+
+	for _, tt := range tests {
+		got := ComputeDistribution(tt.dividends, tt.price)
+
+		if got.DividendSchedule != tt.want.DividendSchedule ||
+			!almostEqual(got.Yield12Month, tt.want.Yield12Month) ||
+			!almostEqual(got.DistYield, tt.want.DistYield) ||
+			!almostEqual(got.DistAmount, tt.want.DistAmount) {
+			t.Errorf("%s: ComputeDistribution() = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+// almostEqual reports whether two figures agree to rounding
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
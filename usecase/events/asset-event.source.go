@@ -0,0 +1,17 @@
+package events
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Asset Event Source Interface
+///////////////////////////////////////////////////////////
+
+// EventSource interface
+type EventSource interface {
+	// FetchEvents fetches the dividend and split events of an asset between from and to (unix seconds)
+	FetchEvents(ctx context.Context, asset *entities.Asset, from int64, to int64) ([]*entities.AssetEvent, error)
+}
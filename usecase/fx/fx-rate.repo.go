@@ -0,0 +1,27 @@
+package fx
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Fx Rate Repository Interface
+///////////////////////////////////////////////////////////
+
+// Reader interface
+type Reader interface {
+	FindFxRates(ctx context.Context, to string) ([]*entities.FxRate, error)
+}
+
+// Writer interface
+type Writer interface {
+	UpsertFxRates(ctx context.Context, fxRates []*entities.FxRate) error
+}
+
+// Repo interface
+type Repo interface {
+	Reader
+	Writer
+}
@@ -0,0 +1,117 @@
+package fx
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// Service sector
+type Service struct {
+	fxRateRepo   Repo
+	baseCurrency string
+	// maxRateAge is how old a rate may be and still convert amounts, zero never expires rates
+	maxRateAge time.Duration
+	log        logger.ContextLog
+	mu         sync.Mutex
+	// rates are the stored rates to the base currency keyed by currency, loaded on first use
+	rates map[string]*entities.FxRate
+}
+
+// NewService create new service
+func NewService(fxRateRepo Repo, baseCurrency string, maxRateAgeMS uint64, log logger.ContextLog) *Service {
+	return &Service{
+		fxRateRepo:   fxRateRepo,
+		baseCurrency: strings.ToUpper(baseCurrency),
+		maxRateAge:   time.Duration(maxRateAgeMS) * time.Millisecond,
+		log:          log,
+	}
+}
+
+// PairSymbol returns the yahoo symbol of the pair quoting from in to, e.g. USDCAD=X
+func PairSymbol(from string, to string) string {
+	return strings.ToUpper(from) + strings.ToUpper(to) + "=X"
+}
+
+// BaseCurrency returns the currency prices are converted to
+func (s *Service) BaseCurrency() string {
+	return s.baseCurrency
+}
+
+// AddFxRates stores the latest rates of currency pairs
+func (s *Service) AddFxRates(ctx context.Context, fxRates []*entities.FxRate) error {
+	s.log.Info(ctx, "adding fx rates", "numRates", len(fxRates))
+
+	if err := s.fxRateRepo.UpsertFxRates(ctx, fxRates); err != nil {
+		return err
+	}
+
+	// the next conversion reloads the rates
+	s.mu.Lock()
+	s.rates = nil
+	s.mu.Unlock()
+
+	return nil
+}
+
+// ToBase converts an amount in the given currency to the base currency with the stored rates.
+// Returns false when there is no rate for the currency, or when it is older than the max rate age
+func (s *Service) ToBase(ctx context.Context, amount float64, currency string) (float64, bool) {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		return 0, false
+	}
+
+	if currency == s.baseCurrency {
+		return amount, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rates == nil {
+		fxRates, err := s.fxRateRepo.FindFxRates(ctx, s.baseCurrency)
+		if err != nil {
+			s.log.Error(ctx, "find fx rates failed", "error", err, "baseCurrency", s.baseCurrency)
+			return 0, false
+		}
+
+		s.rates = map[string]*entities.FxRate{}
+		for _, fxRate := range fxRates {
+			if fxRate.Rate > 0 {
+				s.rates[fxRate.From] = fxRate
+			}
+		}
+	}
+
+	fxRate, ok := s.rates[currency]
+	if !ok {
+		return 0, false
+	}
+
+	if s.isStale(fxRate, time.Now()) {
+		s.log.Info(ctx, "fx rate is stale", "pair", fxRate.Pair, "marketTime", fxRate.MarketTime, "observedAt", fxRate.ObservedAt)
+		return 0, false
+	}
+
+	return amount * fxRate.Rate, true
+}
+
+// isStale reports whether a rate was quoted longer than the max rate age before now, the time it
+// was observed stands in for rates without a market time
+func (s *Service) isStale(fxRate *entities.FxRate, now time.Time) bool {
+	if s.maxRateAge <= 0 {
+		return false
+	}
+
+	quotedAt := fxRate.MarketTime
+	if quotedAt == 0 {
+		quotedAt = fxRate.ObservedAt
+	}
+
+	return now.Sub(time.Unix(quotedAt, 0)) > s.maxRateAge
+}
@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// fakeFxRateRepo holds the stored rates
+type fakeFxRateRepo struct {
+	Repo
+	rates []*entities.FxRate
+}
+
+func (r *fakeFxRateRepo) FindFxRates(ctx context.Context, to string) ([]*entities.FxRate, error) {
+	return r.rates, nil
+}
+
+func TestToBaseRejectsStaleRates(t *testing.T) {
+	zap, err := logger.NewZapLogger()
+	if err != nil {
+		t.Fatalf("NewZapLogger() error = %v", err)
+	}
+	defer zap.Close()
+
+	now := time.Now().UTC()
+	repo := &fakeFxRateRepo{rates: []*entities.FxRate{
+		{Pair: "USDCAD=X", From: "USD", To: "CAD", Rate: 1.35, MarketTime: now.Add(-time.Hour).Unix()},
+		{Pair: "EURCAD=X", From: "EUR", To: "CAD", Rate: 1.45, MarketTime: now.Add(-10 * 24 * time.Hour).Unix()},
+		// without a market time the observed time is used
+		{Pair: "GBPCAD=X", From: "GBP", To: "CAD", Rate: 1.7, ObservedAt: now.Add(-time.Hour).Unix()},
+	}}
+
+	service := NewService(repo, "cad", uint64((4*24*time.Hour)/time.Millisecond), zap)
+	ctx := context.Background()
+
+	tests := []struct {
+		currency string
+		want     float64
+		wantOK   bool
+	}{
+		{currency: "usd", want: 135, wantOK: true},
+		{currency: "GBP", want: 170, wantOK: true},
+		{currency: "CAD", want: 100, wantOK: true},
+		{currency: "EUR", wantOK: false},
+		{currency: "JPY", wantOK: false},
+		{currency: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := service.ToBase(ctx, 100, tt.currency)
+		if ok != tt.wantOK || (ok && (got < tt.want-1e-9 || got > tt.want+1e-9)) {
+			t.Errorf("ToBase(100, %q) = %v, %v, want %v, %v", tt.currency, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// rates never expire without a max age
+	service = NewService(repo, "CAD", 0, zap)
+	if _, ok := service.ToBase(ctx, 100, "EUR"); !ok {
+		t.Errorf("ToBase(EUR) without a max rate age, want the old rate used")
+	}
+}
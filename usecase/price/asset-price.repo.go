@@ -12,11 +12,16 @@ import (
 
 // Reader interface
 type Reader interface {
+	FindAssetPrice(ctx context.Context, ticker string) (*entities.AssetPrice, error)
+	FindAssetPriceHistory(ctx context.Context, ticker string, from int64, to int64) ([]*entities.AssetPrice, error)
 }
 
 // Writer interface
 type Writer interface {
 	InsertAssetPrice(ctx context.Context, assetPrice *entities.AssetPrice) error
+	InsertAssetPriceHistory(ctx context.Context, assetPrice *entities.AssetPrice) error
+	UpdateExtendedHoursPrice(ctx context.Context, assetPrice *entities.AssetPrice) error
+	AdjustAssetPriceHistory(ctx context.Context, ticker string, before int64, factor float64) (int64, error)
 }
 
 // Repo interface
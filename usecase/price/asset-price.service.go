@@ -2,6 +2,7 @@ package price
 
 import (
 	"context"
+	"time"
 
 	logger "github.com/lenoobz/aws-lambda-logger"
 	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
@@ -21,8 +22,52 @@ func NewService(assetPriceRepo Repo, log logger.ContextLog) *Service {
 	}
 }
 
-// AddAssetPrice creates new asset price
+// AddAssetPrice creates new asset price and appends it to the price history
 func (s *Service) AddAssetPrice(ctx context.Context, assetPrice *entities.AssetPrice) error {
 	s.log.Info(ctx, "adding asset price", "ticker", assetPrice.Ticker)
-	return s.assetPriceRepo.InsertAssetPrice(ctx, assetPrice)
+
+	if assetPrice.ObservedAt == 0 {
+		assetPrice.ObservedAt = time.Now().UTC().Unix()
+	}
+
+	if err := s.assetPriceRepo.InsertAssetPrice(ctx, assetPrice); err != nil {
+		return err
+	}
+
+	return s.assetPriceRepo.InsertAssetPriceHistory(ctx, assetPrice)
+}
+
+// AddExtendedHoursPrice stores pre-market and after-hours prices without touching the
+// regular market price, and appends the quote to the price history
+func (s *Service) AddExtendedHoursPrice(ctx context.Context, assetPrice *entities.AssetPrice) error {
+	s.log.Info(ctx, "adding extended hours price", "ticker", assetPrice.Ticker, "marketState", assetPrice.MarketState)
+
+	if assetPrice.ObservedAt == 0 {
+		assetPrice.ObservedAt = time.Now().UTC().Unix()
+	}
+
+	if err := s.assetPriceRepo.UpdateExtendedHoursPrice(ctx, assetPrice); err != nil {
+		return err
+	}
+
+	return s.assetPriceRepo.InsertAssetPriceHistory(ctx, assetPrice)
+}
+
+// GetAssetPrice gets the latest price of a ticker, nil when the ticker has no price yet
+func (s *Service) GetAssetPrice(ctx context.Context, ticker string) (*entities.AssetPrice, error) {
+	s.log.Info(ctx, "getting asset price", "ticker", ticker)
+	return s.assetPriceRepo.FindAssetPrice(ctx, ticker)
+}
+
+// GetAssetPriceHistory gets price history of a ticker between from and to (unix seconds, inclusive)
+func (s *Service) GetAssetPriceHistory(ctx context.Context, ticker string, from int64, to int64) ([]*entities.AssetPrice, error) {
+	s.log.Info(ctx, "getting asset price history", "ticker", ticker, "from", from, "to", to)
+	return s.assetPriceRepo.FindAssetPriceHistory(ctx, ticker, from, to)
+}
+
+// AdjustAssetPriceHistory applies an adjustment factor to the price history of a ticker quoted
+// before the given time (unix seconds), returns the number of adjusted observations
+func (s *Service) AdjustAssetPriceHistory(ctx context.Context, ticker string, before int64, factor float64) (int64, error) {
+	s.log.Info(ctx, "adjusting asset price history", "ticker", ticker, "before", before, "factor", factor)
+	return s.assetPriceRepo.AdjustAssetPriceHistory(ctx, ticker, before, factor)
 }
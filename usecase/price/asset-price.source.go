@@ -0,0 +1,59 @@
+package price
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Asset Price Source Interface
+///////////////////////////////////////////////////////////
+
+// PriceSource interface
+type PriceSource interface {
+	// Name returns the name recorded as the source of the fetched prices
+	Name() string
+	// FetchAssetPrices fetches the latest prices for a batch of assets. Assets whose price
+	// could not be fetched are reported in the returned map keyed by ticker, as a *FetchError
+	FetchAssetPrices(ctx context.Context, assets []*entities.Asset) ([]*entities.AssetPrice, map[string]error)
+}
+
+// FetchError is the error a price source reports for a ticker it could not fetch
+type FetchError struct {
+	// Class is one of the scrape error classes
+	Class string
+	// Status is the HTTP status code of the response, zero when there was no response
+	Status int
+	// SnippetHash is the hash of the beginning of the response body, empty when there was no body
+	SnippetHash string
+	// Successor is the symbol yahoo redirected the ticker to, empty when it was not redirected
+	Successor string
+	Err       error
+}
+
+// IsDelisted reports whether the error suggests the ticker was delisted or renamed
+func (e *FetchError) IsDelisted() bool {
+	switch e.Class {
+	case consts.NOT_FOUND_ERROR, consts.LOOKUP_ERROR, consts.RENAMED_ERROR:
+		return true
+	default:
+		return false
+	}
+}
+
+// Error returns the error message
+func (e *FetchError) Error() string {
+	if e.Status != 0 {
+		return fmt.Sprintf("%s error with status code %d: %v", e.Class, e.Status, e.Err)
+	}
+
+	return fmt.Sprintf("%s error: %v", e.Class, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
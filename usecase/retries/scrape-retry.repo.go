@@ -0,0 +1,28 @@
+package retries
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Scrape Retry Repository Interface
+///////////////////////////////////////////////////////////
+
+// Reader interface
+type Reader interface {
+	FindScrapeRetries(ctx context.Context, limit int64) ([]*entities.ScrapeRetry, error)
+}
+
+// Writer interface
+type Writer interface {
+	InsertScrapeRetries(ctx context.Context, retries []*entities.ScrapeRetry) error
+	DeleteScrapeRetries(ctx context.Context, tickers []string) error
+}
+
+// Repo interface
+type Repo interface {
+	Reader
+	Writer
+}
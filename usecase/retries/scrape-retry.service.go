@@ -0,0 +1,53 @@
+package retries
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// Service sector
+type Service struct {
+	retryRepo Repo
+	log       logger.ContextLog
+}
+
+// NewService create new service
+func NewService(retryRepo Repo, log logger.ContextLog) *Service {
+	return &Service{
+		retryRepo: retryRepo,
+		log:       log,
+	}
+}
+
+// AddScrapeRetries queues the failed tickers for the next run
+func (s *Service) AddScrapeRetries(ctx context.Context, failures map[string]error) error {
+	s.log.Info(ctx, "adding scrape retries", "numRetries", len(failures))
+
+	now := time.Now().UTC().Unix()
+
+	var retries []*entities.ScrapeRetry
+	for ticker, err := range failures {
+		retries = append(retries, &entities.ScrapeRetry{
+			Ticker:    ticker,
+			LastError: err.Error(),
+			FailedAt:  now,
+		})
+	}
+
+	return s.retryRepo.InsertScrapeRetries(ctx, retries)
+}
+
+// GetScrapeRetries gets the oldest queued retries, at most limit of them
+func (s *Service) GetScrapeRetries(ctx context.Context, limit int64) ([]*entities.ScrapeRetry, error) {
+	s.log.Info(ctx, "getting scrape retries", "limit", limit)
+	return s.retryRepo.FindScrapeRetries(ctx, limit)
+}
+
+// RemoveScrapeRetries removes the tickers from the retry queue
+func (s *Service) RemoveScrapeRetries(ctx context.Context, tickers []string) error {
+	s.log.Info(ctx, "removing scrape retries", "numTickers", len(tickers))
+	return s.retryRepo.DeleteScrapeRetries(ctx, tickers)
+}
@@ -0,0 +1,55 @@
+package symbols
+
+import (
+	"strings"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/consts"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// quoteTypes maps the asset types our assets use, and the yahoo quote types, to the quote types.
+// Keys are upper case without spaces, dashes or underscores
+var quoteTypes = map[string]string{
+	"EQUITY":         consts.EQUITY_QUOTE_TYPE,
+	"STOCK":          consts.EQUITY_QUOTE_TYPE,
+	"COMMONSTOCK":    consts.EQUITY_QUOTE_TYPE,
+	"ETF":            consts.ETF_QUOTE_TYPE,
+	"MUTUALFUND":     consts.MUTUAL_FUND_QUOTE_TYPE,
+	"FUND":           consts.MUTUAL_FUND_QUOTE_TYPE,
+	"MF":             consts.MUTUAL_FUND_QUOTE_TYPE,
+	"INDEX":          consts.INDEX_QUOTE_TYPE,
+	"CRYPTO":         consts.CRYPTO_QUOTE_TYPE,
+	"CRYPTOCURRENCY": consts.CRYPTO_QUOTE_TYPE,
+	"FUTURE":         consts.FUTURE_QUOTE_TYPE,
+	"FUTURES":        consts.FUTURE_QUOTE_TYPE,
+	"CURRENCY":       consts.CURRENCY_QUOTE_TYPE,
+	"FX":             consts.CURRENCY_QUOTE_TYPE,
+}
+
+// NormalizeQuoteType returns the quote type of an asset type or a yahoo quote type, empty when
+// it is not known
+func NormalizeQuoteType(assetType string) string {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToUpper(assetType))
+	return quoteTypes[key]
+}
+
+// QuoteType returns the quote type of an asset from its type. Assets without a known type are
+// recognized by the shape of their yahoo symbol, ^GSPC is an index, CL=F a future and USDCAD=X a
+// currency pair, and are equities otherwise
+func QuoteType(asset *entities.Asset) string {
+	if quoteType := NormalizeQuoteType(asset.Type); quoteType != "" {
+		return quoteType
+	}
+
+	symbol := YahooSymbol(asset)
+	switch {
+	case strings.HasPrefix(symbol, "^"):
+		return consts.INDEX_QUOTE_TYPE
+	case strings.HasSuffix(symbol, "=F"):
+		return consts.FUTURE_QUOTE_TYPE
+	case strings.HasSuffix(symbol, "=X"):
+		return consts.CURRENCY_QUOTE_TYPE
+	default:
+		return consts.EQUITY_QUOTE_TYPE
+	}
+}
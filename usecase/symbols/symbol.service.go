@@ -0,0 +1,63 @@
+package symbols
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	logger "github.com/lenoobz/aws-lambda-logger"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/usecase/assets"
+)
+
+// Service sector
+type Service struct {
+	symbolSource SymbolSource
+	assetService *assets.Service
+	log          logger.ContextLog
+}
+
+// NewService create new service
+func NewService(symbolSource SymbolSource, assetService *assets.Service, log logger.ContextLog) *Service {
+	return &Service{
+		symbolSource: symbolSource,
+		assetService: assetService,
+		log:          log,
+	}
+}
+
+// SearchSymbols searches the symbols matching a name, a symbol or an ISIN
+func (s *Service) SearchSymbols(ctx context.Context, query string) ([]*entities.SymbolCandidate, error) {
+	s.log.Info(ctx, "searching symbols", "query", query)
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+
+	return s.symbolSource.SearchSymbols(ctx, query)
+}
+
+// AddSymbol inserts a found symbol as a new asset. The asset ticker is the symbol unless another
+// ticker is given, the symbol is kept as the yahoo symbol override so it is scraped as found
+func (s *Service) AddSymbol(ctx context.Context, candidate *entities.SymbolCandidate, ticker string) (*entities.Asset, error) {
+	if ticker = strings.TrimSpace(ticker); ticker == "" {
+		ticker = candidate.Symbol
+	}
+
+	asset := &entities.Asset{
+		Ticker:      strings.ToUpper(ticker),
+		Name:        candidate.Name,
+		Type:        candidate.Type,
+		Currency:    candidate.Currency,
+		YahooSymbol: strings.ToUpper(candidate.Symbol),
+	}
+
+	s.log.Info(ctx, "adding symbol", "ticker", asset.Ticker, "symbol", asset.YahooSymbol)
+	if err := s.assetService.AddAsset(ctx, asset); err != nil {
+		s.log.Error(ctx, "add asset failed", "error", err, "ticker", asset.Ticker)
+		return nil, err
+	}
+
+	return asset, nil
+}
@@ -0,0 +1,17 @@
+package symbols
+
+import (
+	"context"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+///////////////////////////////////////////////////////////
+// Symbol Source Interface
+///////////////////////////////////////////////////////////
+
+// SymbolSource interface
+type SymbolSource interface {
+	// SearchSymbols returns the symbols matching a name, a symbol or an ISIN
+	SearchSymbols(ctx context.Context, query string) ([]*entities.SymbolCandidate, error)
+}
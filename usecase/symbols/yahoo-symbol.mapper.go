@@ -0,0 +1,73 @@
+package symbols
+
+import (
+	"strings"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+// exchangeSuffixes are the suffixes yahoo appends to the symbols of an exchange, keyed by the
+// exchange codes and aliases our assets use, and by the yahoo exchange codes filled in by the
+// enrichment job. US exchanges have no suffix
+var exchangeSuffixes = map[string]string{
+	"TSX":      ".TO",
+	"TSE":      ".TO",
+	"XTSE":     ".TO",
+	"TSXV":     ".V",
+	"CVE":      ".V",
+	"XTSX":     ".V",
+	"NEO":      ".NE",
+	"CBOE CA":  ".NE",
+	"CSE":      ".CN",
+	"CNSX":     ".CN",
+	"NYSE":     "",
+	"NYSEARCA": "",
+	"ARCA":     "",
+	"AMEX":     "",
+	"NASDAQ":   "",
+	"BATS":     "",
+	"CBOE":     "",
+	"LSE":      ".L",
+	"ASX":      ".AX",
+	"TOR":      ".TO",
+	"VAN":      ".V",
+	"CNQ":      ".CN",
+	"NYQ":      "",
+	"NMS":      "",
+	"NGM":      "",
+	"NCM":      "",
+	"PCX":      "",
+	"ASE":      "",
+	"BTS":      "",
+}
+
+// YahooSymbol returns the yahoo symbol of an asset. The symbol override of the asset wins, otherwise
+// the share class separator of the ticker becomes a dash and the suffix of its exchange is
+// appended. The exchange is the one of the asset, or the prefix of an exchange qualified ticker
+// like TSX:VFV. Tickers of an unknown exchange, like 7203.T, and tickers that already end with the
+// yahoo suffix of their exchange are kept as they are
+func YahooSymbol(asset *entities.Asset) string {
+	if override := strings.TrimSpace(asset.YahooSymbol); override != "" {
+		return strings.ToUpper(override)
+	}
+
+	ticker := strings.ToUpper(strings.TrimSpace(asset.Ticker))
+	exchange := strings.ToUpper(strings.TrimSpace(asset.Exchange))
+
+	if i := strings.Index(ticker, ":"); i >= 0 {
+		exchange, ticker = ticker[:i], ticker[i+1:]
+	}
+
+	// the dot of a ticker of an exchange we do not know may be its yahoo suffix
+	suffix, knownExchange := exchangeSuffixes[exchange]
+	if !knownExchange {
+		return ticker
+	}
+
+	if suffix != "" && strings.HasSuffix(ticker, suffix) {
+		return ticker
+	}
+
+	// yahoo writes share classes like BRK.B and RCI.B as BRK-B and RCI-B
+	return strings.ReplaceAll(ticker, ".", "-") + suffix
+}
@@ -0,0 +1,39 @@
+package symbols
+
+import (
+	"testing"
+
+	"github.com/lenoobz/aws-yahoo-asset-price-scraper/entities"
+)
+
+func TestYahooSymbol(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset entities.Asset
+		want  string
+	}{
+		{name: "us ticker", asset: entities.Asset{Ticker: "aapl", Exchange: "NASDAQ"}, want: "AAPL"},
+		{name: "no exchange", asset: entities.Asset{Ticker: "MSFT"}, want: "MSFT"},
+		{name: "tsx suffix", asset: entities.Asset{Ticker: "XIU", Exchange: "TSX"}, want: "XIU.TO"},
+		{name: "yahoo exchange code", asset: entities.Asset{Ticker: "SHOP", Exchange: "tor"}, want: "SHOP.TO"},
+		{name: "venture suffix", asset: entities.Asset{Ticker: "ABC", Exchange: "TSXV"}, want: "ABC.V"},
+		{name: "us share class", asset: entities.Asset{Ticker: "BRK.B", Exchange: "NYSE"}, want: "BRK-B"},
+		{name: "tsx share class", asset: entities.Asset{Ticker: "RCI.B", Exchange: "TSX"}, want: "RCI-B.TO"},
+		{name: "exchange qualified", asset: entities.Asset{Ticker: "TSX:VFV"}, want: "VFV.TO"},
+		{name: "qualifier wins", asset: entities.Asset{Ticker: "NEO:ABC", Exchange: "TSX"}, want: "ABC.NE"},
+		{name: "already suffixed", asset: entities.Asset{Ticker: "XIU.TO", Exchange: "TSX"}, want: "XIU.TO"},
+		{name: "suffixed without exchange", asset: entities.Asset{Ticker: "VOD.L"}, want: "VOD.L"},
+		{name: "suffix of another exchange", asset: entities.Asset{Ticker: "ABC.V", Exchange: "TSX"}, want: "ABC-V.TO"},
+		{name: "override", asset: entities.Asset{Ticker: "BRKB", Exchange: "TSX", YahooSymbol: " brk-b "}, want: "BRK-B"},
+		{name: "unknown exchange", asset: entities.Asset{Ticker: "7203", Exchange: "TYO"}, want: "7203"},
+		{name: "suffixed of an unknown exchange", asset: entities.Asset{Ticker: "7203.T", Exchange: "TYO"}, want: "7203.T"},
+		{name: "suffixed of an unknown qualifier", asset: entities.Asset{Ticker: "JPX:7203.T"}, want: "7203.T"},
+		{name: "share class without exchange", asset: entities.Asset{Ticker: "BRK.B"}, want: "BRK.B"},
+	}
+
+	for _, tt := range tests {
+		if got := YahooSymbol(&tt.asset); got != tt.want {
+			t.Errorf("%s: YahooSymbol(%+v) = %q, want %q", tt.name, tt.asset, got, tt.want)
+		}
+	}
+}